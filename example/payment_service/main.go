@@ -20,6 +20,8 @@ func main() {
 		AppSecret:  os.Getenv("PAYARA_APP_SECRET"),
 		HTTPClient: &http.Client{Timeout: 15 * time.Second},
 		Logger:     &payara.NopLogger{},
+		// Retries balance and status reads; create-disbursement is never retried by it.
+		RetryPolicy: payara.DefaultRetryPolicy(),
 	}
 	if cfg.AppID == "" || cfg.AppSecret == "" {
 		log.Fatal("set PAYARA_APP_ID and PAYARA_APP_SECRET")
@@ -27,8 +29,7 @@ func main() {
 
 	client := payara.NewClient(cfg).
 		WithEnvironment(payara.EnvironmentSandbox).
-		WithTimeout(15 * time.Second).
-		WithRetryPolicy(payara.DefaultRetryPolicy())
+		WithTimeout(15 * time.Second)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -40,7 +41,7 @@ func main() {
 		log.Fatalf("balance: %v", err)
 	}
 	if bal.Data != nil {
-		log.Printf("balance: %d %s", bal.Data.Balance, bal.Data.Currency)
+		log.Printf("balance: %s (%s)", bal.Data.Balance, bal.Data.Currency)
 	} else {
 		log.Printf("balance: %s", bal.Message)
 	}
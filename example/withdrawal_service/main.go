@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"time"
@@ -17,6 +18,8 @@ func main() {
 		BaseURL:   payara.BaseURLForEnvironment(payara.EnvironmentSandbox),
 		AppID:     os.Getenv("PAYARA_APP_ID"),
 		AppSecret: os.Getenv("PAYARA_APP_SECRET"),
+		// Estimated fees; learned from responses once disbursements have been made.
+		FeeSchedule: payara.NewLearnedFeeSchedule(payara.StaticFeeSchedule{Default: 2500}),
 	}
 	if cfg.AppID == "" || cfg.AppSecret == "" {
 		log.Fatal("set PAYARA_APP_ID and PAYARA_APP_SECRET")
@@ -25,14 +28,6 @@ func main() {
 	client := payara.NewClient(cfg).WithEnvironment(payara.EnvironmentSandbox)
 	ctx := context.Background()
 
-	bal, err := client.Balance().GetBalance(ctx)
-	if err != nil {
-		log.Fatalf("balance: %v", err)
-	}
-	if bal.Data != nil && bal.Data.Balance < 50000 {
-		log.Fatal("insufficient balance")
-	}
-
 	recipient := payara.DefaultSandboxAccount()
 	req := types.CreateDisbursementRequest{
 		ReferenceID:   "WD-" + time.Now().Format("20060102150405"),
@@ -42,6 +37,13 @@ func main() {
 		AccountName:   recipient.AccountName,
 		Description:   "Withdrawal (sandbox dummy)",
 	}
+	var short *payara.InsufficientBalanceError
+	if _, err := client.Balance().EnsureSufficient(ctx, payara.PayoutOf(req)); errors.As(err, &short) {
+		log.Fatalf("insufficient balance: short %s", short.Shortfall)
+	} else if err != nil {
+		log.Fatalf("balance: %v", err)
+	}
+
 	resp, err := client.Transfer().CreateDisbursement(ctx, req)
 	if err != nil {
 		log.Fatalf("disbursement: %v", err)
@@ -0,0 +1,17 @@
+package payara
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+const checkAccountPath = "/api/v1/check-account"
+
+// CheckAccount sends POST /api/v1/check-account. Doc: Check Account
+// Use it to confirm the beneficiary name before CreateDisbursement.
+func (s *accountService) CheckAccount(ctx context.Context, req types.CheckAccountRequest) (*types.CheckAccountResponse, error) {
+	ctx = ContextWithOperation(ctx, OperationCheckAccount)
+	return Do[types.CheckAccountResponseData](ctx, s.client, http.MethodPost, checkAccountPath, req)
+}
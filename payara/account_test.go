@@ -0,0 +1,96 @@
+package payara
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+func TestAccountService_CheckAccount_Mock(t *testing.T) {
+	loginBody := []byte(`{"success":true,"message":"ok","data":{"access_token":"tok","token_type":"Bearer","expires_in":3600,"merchant_id":"M1","merchant_name":"Test"}}`)
+	checkBody := []byte(`{"success":true,"message":"ok","data":{"bank_code":"5","bank_name":"Bank Central Asia","account_number":"12330922231","account_name":"Asep","is_valid":true}}`)
+
+	mock := &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			var body []byte
+			switch req.URL.Path {
+			case "/api/v1/login":
+				body = loginBody
+			case "/api/v1/check-account":
+				if req.Method != http.MethodPost {
+					t.Errorf("method: got %s", req.Method)
+				}
+				if got := req.Header.Get("Authorization"); got != "Bearer tok" {
+					t.Errorf("Authorization: got %q", got)
+				}
+				var sent types.CheckAccountRequest
+				if err := json.NewDecoder(req.Body).Decode(&sent); err != nil {
+					t.Fatal(err)
+				}
+				if sent.BankCode != "5" || sent.AccountNumber != "12330922231" {
+					t.Errorf("request body: got %+v", sent)
+				}
+				body = checkBody
+			default:
+				t.Fatalf("unexpected path: %s", req.URL.Path)
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+			}, nil
+		},
+	}
+	client := NewClient(&Config{
+		AppID:      "app",
+		AppSecret:  "secret",
+		BaseURL:    "https://test.payara.id",
+		HTTPClient: &http.Client{Transport: mock},
+	})
+	resp, err := client.Accounts().CheckAccount(context.Background(), types.CheckAccountRequest{BankCode: "5", AccountNumber: "12330922231"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data == nil || resp.Data.AccountName != "Asep" || resp.Data.BankName != "Bank Central Asia" || !resp.Data.IsValid {
+		t.Errorf("unexpected data: %+v", resp.Data)
+	}
+}
+
+func TestAccountService_CheckAccount_Error(t *testing.T) {
+	loginBody := []byte(`{"success":true,"message":"ok","data":{"access_token":"tok","token_type":"Bearer","expires_in":3600,"merchant_id":"M1","merchant_name":"Test"}}`)
+	errBody := []byte(`{"success":false,"message":"Account not found","error_code":"INVALID_ACCOUNT"}`)
+
+	mock := &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			status, body := 200, loginBody
+			if req.URL.Path == "/api/v1/check-account" {
+				status, body = 400, errBody
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+			}, nil
+		},
+	}
+	client := NewClient(&Config{
+		AppID:      "app",
+		AppSecret:  "secret",
+		BaseURL:    "https://test.payara.id",
+		HTTPClient: &http.Client{Transport: mock},
+	})
+	_, err := client.Accounts().CheckAccount(context.Background(), types.CheckAccountRequest{BankCode: "5", AccountNumber: "0"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+	if apiErr.Code != "INVALID_ACCOUNT" || apiErr.HTTPStatus != 400 {
+		t.Errorf("got %+v", apiErr)
+	}
+}
@@ -2,96 +2,293 @@ package payara
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/turahe/payara-go-sdk/payara/types"
 )
 
 const (
-	loginPath   = "/api/v1/login"
-	tokenBuffer = 5 * time.Minute // Refresh token before expiry
+	loginPath    = "/api/v1/login"
+	tokenBuffer  = 5 * time.Minute  // Refresh token before expiry
+	refreshLead  = time.Minute      // Requests start a refresh this long before tokenBuffer is reached
+	refreshRetry = 30 * time.Second // Delay before retrying a failed early refresh
+	loginTimeout = 30 * time.Second // Upper bound for one login, independent of any caller's context
 )
 
 var errUnauthorized = &APIError{Code: "UNAUTHORIZED", Message: "invalid or expired token"}
 
-// login performs POST /api/v1/login and updates client token. Doc: username=app_id, password=app_secret
-func (c *Client) login(ctx context.Context) error {
-	body := types.LoginRequest{Username: c.appID, Password: c.appSecret}
-	req, err := newJSONRequest(ctx, http.MethodPost, c.baseURL+loginPath, body)
-	if err != nil {
-		return err
+// authState holds the access token shared by a Client and its clones, and coordinates refreshes:
+// at most one login is in flight and concurrent callers wait for it, each bounded by its own context.
+// A request that finds the token within refreshLead of tokenBuffer starts a refresh without waiting for it,
+// so busy clients normally never wait for a login round-trip. An idle client makes no logins unless
+// background is set, in which case a timer also refreshes the token ahead of time until close.
+type authState struct {
+	fetch  tokenFetcher
+	logger Logger
+	now    func() time.Time
+	buffer time.Duration // tokenBuffer
+	lead   time.Duration // refreshLead
+
+	mu       sync.Mutex
+	token    string
+	expiry   time.Time
+	inflight *loginCall
+	failedAt time.Time // last failed login, to pace early refreshes
+
+	background bool        // Config.BackgroundTokenRefresh
+	timer      *time.Timer // next background refresh
+	closed     bool        // close was called; no more timers
+}
+
+// tokenFetcher obtains a new token, logging in through hc. stale, when non-empty, is a token the API just
+// rejected and must not be returned.
+type tokenFetcher func(ctx context.Context, hc *http.Client, stale string) (string, time.Time, error)
+
+// loginCall is one in-flight login; done is closed when token/err are set.
+type loginCall struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+func newAuthState(fetch tokenFetcher, logger Logger) *authState {
+	return &authState{fetch: fetch, logger: logger, now: time.Now, buffer: tokenBuffer, lead: refreshLead}
+}
+
+// validLocked reports whether the cached token is usable for at least a.buffer. Call with a.mu held.
+func (a *authState) validLocked() bool {
+	return a.token != "" && a.now().Add(a.buffer).Before(a.expiry)
+}
+
+// get returns a valid token, starting or joining a login if needed. A login started here is sent through hc,
+// the caller's HTTP client. When the token is close to the refresh buffer it is returned at once and a
+// refresh is started for later callers.
+func (a *authState) get(ctx context.Context, hc *http.Client) (string, error) {
+	a.mu.Lock()
+	if a.validLocked() {
+		t := a.token
+		if a.refreshDueLocked() {
+			a.startLocked(ctx, hc, "")
+		}
+		a.mu.Unlock()
+		return t, nil
 	}
-	// Login does not use Bearer; only subsequent API calls do
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	call := a.startLocked(ctx, hc, "")
+	a.mu.Unlock()
+	return call.wait(ctx)
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return err
+// refreshDueLocked reports whether a valid token should be refreshed ahead of time: it is within a.lead of
+// the buffer and no early refresh failed in the last refreshRetry. Call with a.mu held.
+func (a *authState) refreshDueLocked() bool {
+	now := a.now()
+	return !now.Add(a.buffer+a.lead).Before(a.expiry) && now.Sub(a.failedAt) >= refreshRetry
+}
+
+// renew forces a new login after stale was rejected (HTTP 401). If another caller has already replaced
+// stale, the new token is returned without logging in again.
+func (a *authState) renew(ctx context.Context, hc *http.Client, stale string) (string, error) {
+	for attempt := 0; ; attempt++ {
+		a.mu.Lock()
+		if a.token != stale && a.validLocked() {
+			t := a.token
+			a.mu.Unlock()
+			return t, nil
+		}
+		call := a.startLocked(ctx, hc, stale)
+		a.mu.Unlock()
+		t, err := call.wait(ctx)
+		if err != nil || t != stale || attempt > 0 {
+			return t, err
+		}
+		// Joined a login started without the stale hint that handed back the rejected token; try once more.
 	}
-	defer resp.Body.Close()
+}
 
-	raw, _ := readAll(resp.Body)
-	var loginResp types.LoginResponse
-	if err := json.Unmarshal(raw, &loginResp); err != nil {
-		return &APIError{Message: "login response decode failed", RawBody: raw, HTTPStatus: resp.StatusCode}
+// startLocked returns the in-flight login, starting one if none is running. Call with a.mu held.
+// The login is detached from ctx so one caller's cancellation does not fail the others.
+func (a *authState) startLocked(ctx context.Context, hc *http.Client, stale string) *loginCall {
+	if a.inflight != nil {
+		return a.inflight
 	}
-	if !loginResp.Success || loginResp.Data == nil {
-		code := ""
-		var errResp types.ErrorResponse
-		_ = json.Unmarshal(raw, &errResp)
-		if errResp.ErrorCode != "" {
-			code = errResp.ErrorCode
+	call := &loginCall{done: make(chan struct{})}
+	a.inflight = call
+	go a.run(context.WithoutCancel(ctx), hc, call, stale)
+	return call
+}
+
+func (a *authState) run(ctx context.Context, hc *http.Client, call *loginCall, stale string) {
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
+	token, expiry, err := a.fetch(ctx, hc, stale)
+	a.mu.Lock()
+	if err == nil {
+		a.token, a.expiry = token, expiry
+	} else {
+		a.failedAt = a.now()
+		if a.validLocked() {
+			a.logger.Warn("payara early token refresh failed", "error", err)
 		}
-		return &APIError{Code: code, Message: loginResp.Message, HTTPStatus: resp.StatusCode, RawBody: raw}
 	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return &APIError{Message: loginResp.Message, HTTPStatus: resp.StatusCode, RawBody: raw}
+	call.token, call.err = token, err
+	a.inflight = nil
+	if a.background && !a.closed {
+		a.scheduleLocked(hc, err == nil)
+	}
+	a.mu.Unlock()
+	close(call.done)
+}
+
+// scheduleLocked arms the background refresh after a login: when the new token enters the refresh window,
+// or refreshRetry after a failure while the old token is still valid. Once the token has expired the next
+// request logs in and re-arms the timer. Call with a.mu held.
+func (a *authState) scheduleLocked(hc *http.Client, ok bool) {
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
 	}
+	d := refreshRetry
+	if ok {
+		if due := a.expiry.Sub(a.now()) - a.buffer - a.lead; due > 0 {
+			d = due
+		}
+	} else if !a.validLocked() {
+		return
+	}
+	a.timer = time.AfterFunc(d, func() {
+		a.mu.Lock()
+		if !a.closed {
+			a.startLocked(context.Background(), hc, "")
+		}
+		a.mu.Unlock()
+	})
+}
 
-	c.accessToken = loginResp.Data.AccessToken
-	expirySec := loginResp.Data.ExpiresIn
-	if expirySec <= 0 {
-		expirySec = 3600
+// close stops the background refresh. The token stays usable and is refreshed on use.
+func (a *authState) close() {
+	a.mu.Lock()
+	a.closed = true
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
 	}
-	c.tokenExpiry = time.Now().Add(time.Duration(int(expirySec)) * time.Second)
-	if c.auth != nil {
-		c.auth.token = c.accessToken
-		c.auth.expiry = c.tokenExpiry
+	a.mu.Unlock()
+}
+
+func (c *loginCall) wait(ctx context.Context) (string, error) {
+	select {
+	case <-c.done:
+		return c.token, c.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// tokenManager holds the credentials shared by a Client and all its With* clones, and one authState per
+// base URL. Tokens are scoped to base URL and app ID, so a clone pointed at another environment logs in
+// there instead of sending the old environment's token.
+type tokenManager struct {
+	appID      string
+	appSecret  string
+	store      TokenStore
+	logger     Logger
+	background bool // Config.BackgroundTokenRefresh
+
+	mu     sync.Mutex
+	scopes map[string]*authState // TokenKey(baseURL, appID) -> state
+	closed bool
+}
+
+func newTokenManager(appID, appSecret string, store TokenStore, logger Logger, background bool) *tokenManager {
+	return &tokenManager{appID: appID, appSecret: appSecret, store: store, logger: logger, background: background,
+		scopes: make(map[string]*authState)}
+}
+
+// close stops background refresh for every scope, including ones created later.
+func (m *tokenManager) close() {
+	m.mu.Lock()
+	m.closed = true
+	scopes := make([]*authState, 0, len(m.scopes))
+	for _, a := range m.scopes {
+		scopes = append(scopes, a)
+	}
+	m.mu.Unlock()
+	for _, a := range scopes {
+		a.close()
 	}
-	return nil
 }
 
-// getAuthHeader returns the Authorization header value. Call while holding c.mu or after ensureToken.
-func (c *Client) getAuthHeader() string {
-	return "Bearer " + strings.TrimSpace(c.accessToken)
+// scope returns the token state for baseURL, creating it on first use.
+func (m *tokenManager) scope(baseURL string) *authState {
+	key := TokenKey(baseURL, m.appID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if a, ok := m.scopes[key]; ok {
+		return a
+	}
+	login := func(ctx context.Context, hc *http.Client) (string, time.Time, error) {
+		return m.fetchToken(ctx, hc, baseURL)
+	}
+	fetch := func(ctx context.Context, hc *http.Client, _ string) (string, time.Time, error) { return login(ctx, hc) }
+	if m.store != nil {
+		fetch = tokenFromStore(m.store, key, m.logger, login)
+	}
+	a := newAuthState(fetch, m.logger)
+	a.background = m.background && !m.closed
+	m.scopes[key] = a
+	return a
 }
 
-// ensureToken refreshes token if expired or missing. Safe for concurrent use.
-func (c *Client) ensureToken(ctx context.Context) error {
-	c.mu.Lock()
-	needRefresh := c.accessToken == "" || time.Now().Add(tokenBuffer).After(c.tokenExpiry)
-	c.mu.Unlock()
-	if !needRefresh {
-		return nil
+// fetchToken performs POST /api/v1/login against baseURL and returns the access token and its expiry.
+// Doc: username=app_id, password=app_secret
+func (m *tokenManager) fetchToken(ctx context.Context, hc *http.Client, baseURL string) (string, time.Time, error) {
+	body := types.LoginRequest{Username: m.appID, Password: m.appSecret}
+	req, err := newJSONRequest(ContextWithOperation(ctx, OperationLogin), http.MethodPost, baseURL+loginPath, body)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	// Login does not use Bearer; only subsequent API calls do
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	loginResp, err := decodeEnvelope[types.LoginResponseData](resp)
+	if err != nil {
+		return "", time.Time{}, err
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if c.accessToken != "" && time.Now().Add(tokenBuffer).Before(c.tokenExpiry) {
-		return nil
+	if loginResp.Data == nil || loginResp.Data.AccessToken == "" {
+		return "", time.Time{}, &APIError{Message: "login response missing access_token", HTTPStatus: resp.StatusCode}
+	}
+
+	expirySec := loginResp.Data.ExpiresIn
+	if expirySec <= 0 {
+		expirySec = 3600
 	}
-	return c.login(ctx)
+	return loginResp.Data.AccessToken, time.Now().Add(time.Duration(int(expirySec)) * time.Second), nil
+}
+
+// authHeader returns the Authorization header value for token.
+func authHeader(token string) string {
+	return "Bearer " + strings.TrimSpace(token)
 }
 
 // doRequest adds auth and performs the request. Refreshes token on 401 and retries once.
 func (c *Client) doRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
-	if err := c.ensureToken(ctx); err != nil {
+	token, err := c.auth.get(ctx, c.httpClient)
+	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", c.getAuthHeader())
+	req, err = replayableRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", authHeader(token))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
@@ -101,11 +298,16 @@ func (c *Client) doRequest(ctx context.Context, req *http.Request) (*http.Respon
 	}
 	if resp.StatusCode == http.StatusUnauthorized {
 		resp.Body.Close()
-		if err := c.login(ctx); err != nil {
+		token, err = c.auth.renew(ctx, c.httpClient, token)
+		if err != nil {
+			return nil, err
+		}
+		retry, err := rewindRequest(req)
+		if err != nil {
 			return nil, err
 		}
-		req.Header.Set("Authorization", c.getAuthHeader())
-		return c.httpClient.Do(req)
+		retry.Header.Set("Authorization", authHeader(token))
+		return c.httpClient.Do(retry)
 	}
 	return resp, nil
 }
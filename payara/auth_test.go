@@ -0,0 +1,217 @@
+package payara
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/internal/paytest"
+)
+
+const authBalanceBody = `{"success":true,"message":"ok","data":{"balance":"1000","status":"ACTIVE","merchant_name":"M"}}`
+
+// authClient returns a client whose login calls are counted and optionally gated by release.
+// Balance calls succeed only with the token from the most recent login.
+func authClient(logins *int32, release <-chan struct{}) *Client {
+	return mockClient(&paytest.API{
+		Login: func(*http.Request, int) (*http.Response, error) {
+			n := atomic.AddInt32(logins, 1)
+			if release != nil {
+				<-release
+			}
+			return paytest.Login("tok"+string(rune('0'+n)), 3600)
+		},
+		Handler: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") != "Bearer tok"+string(rune('0'+atomic.LoadInt32(logins))) {
+				return paytest.JSON(401, `{"success":false,"message":"invalid token"}`)
+			}
+			return paytest.JSON(200, authBalanceBody)
+		},
+	}, nil)
+}
+
+func TestAuth_ConcurrentRequestsShareOneLogin(t *testing.T) {
+	var logins int32
+	release := make(chan struct{})
+	client := authClient(&logins, release)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Balance().GetBalance(context.Background())
+			errs <- err
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if logins != 1 {
+		t.Errorf("logins = %d, want 1", logins)
+	}
+}
+
+func TestAuth_Concurrent401sReloginOnce(t *testing.T) {
+	var logins int32
+	client := authClient(&logins, nil)
+	if _, err := client.Balance().GetBalance(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Server-side revocation: the cached tok1 is now rejected.
+	atomic.StoreInt32(&logins, 5)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Balance().GetBalance(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if logins != 6 {
+		t.Errorf("logins after revocation = %d, want 6 (one re-login)", logins)
+	}
+}
+
+func TestAuth_WaiterContextCancelled(t *testing.T) {
+	var logins int32
+	release := make(chan struct{})
+	client := authClient(&logins, release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.Balance().GetBalance(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	// The detached login completes and later callers reuse it.
+	close(release)
+	if _, err := client.Balance().GetBalance(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if logins != 1 {
+		t.Errorf("logins = %d, want 1", logins)
+	}
+}
+
+func TestAuth_EarlyRefreshOnUse(t *testing.T) {
+	var fetches int32
+	a := newAuthState(func(context.Context, *http.Client, string) (string, time.Time, error) {
+		n := atomic.AddInt32(&fetches, 1)
+		return "tok" + string(rune('0'+n)), time.Now().Add(100 * time.Millisecond), nil
+	}, NopLogger{})
+	a.buffer, a.lead = 20*time.Millisecond, 40*time.Millisecond
+
+	if tok, err := a.get(context.Background(), nil); err != nil || tok != "tok1" {
+		t.Fatalf("get: %q %v", tok, err)
+	}
+	// Idle: no timer logs in behind the caller's back.
+	time.Sleep(60 * time.Millisecond)
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Fatalf("fetches while idle = %d, want 1", n)
+	}
+	// Inside the refresh window the current token is returned without waiting and a refresh starts.
+	if tok, err := a.get(context.Background(), nil); err != nil || tok != "tok1" {
+		t.Fatalf("get in window: %q %v", tok, err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&fetches) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if tok, err := a.get(context.Background(), nil); err != nil || tok != "tok2" {
+		t.Errorf("after early refresh: %q %v", tok, err)
+	}
+}
+
+func TestAuth_FailedEarlyRefreshIsPaced(t *testing.T) {
+	var fetches int32
+	a := newAuthState(func(context.Context, *http.Client, string) (string, time.Time, error) {
+		if atomic.AddInt32(&fetches, 1) > 1 {
+			return "", time.Time{}, errors.New("login down")
+		}
+		return "tok", time.Now().Add(time.Hour), nil
+	}, NopLogger{})
+	a.buffer, a.lead = 30*time.Minute, 31*time.Minute // always in the refresh window
+
+	if _, err := a.get(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if tok, err := a.get(context.Background(), nil); err != nil || tok != "tok" {
+			t.Fatalf("get %d: %q %v", i, tok, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("fetches = %d, want 2 (one failed early refresh, then paced by refreshRetry)", n)
+	}
+}
+
+func TestAuth_BackgroundRefreshUntilClose(t *testing.T) {
+	var fetches int32
+	a := newAuthState(func(context.Context, *http.Client, string) (string, time.Time, error) {
+		n := atomic.AddInt32(&fetches, 1)
+		return "tok" + string(rune('0'+n)), time.Now().Add(100 * time.Millisecond), nil
+	}, NopLogger{})
+	a.buffer, a.lead = 20*time.Millisecond, 40*time.Millisecond
+	a.background = true
+
+	if _, err := a.get(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	// Idle: the timer refreshes 40ms after each login without any request.
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&fetches) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&fetches); n < 3 {
+		t.Fatalf("fetches while idle = %d, want >= 3", n)
+	}
+	a.mu.Lock()
+	valid := a.validLocked()
+	a.mu.Unlock()
+	if !valid {
+		t.Error("background refresh must keep the token valid")
+	}
+
+	a.close()
+	time.Sleep(10 * time.Millisecond) // let a refresh started just before close finish
+	n := atomic.LoadInt32(&fetches)
+	time.Sleep(150 * time.Millisecond)
+	if got := atomic.LoadInt32(&fetches); got != n {
+		t.Errorf("fetches after close = %d, want %d", got, n)
+	}
+}
+
+func TestClient_CloseStopsBackgroundRefreshForClones(t *testing.T) {
+	c := mockClient(&paytest.API{}, &Config{BackgroundTokenRefresh: true})
+	clone := c.WithEnvironment(EnvironmentSandbox)
+	if !c.auth.background || !clone.auth.background {
+		t.Fatal("background refresh not enabled")
+	}
+	if err := clone.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.auth.closed || !clone.auth.closed {
+		t.Error("Close must stop every scope shared by the clones")
+	}
+	if other := c.tokens.scope("https://other.example"); other.background {
+		t.Error("scopes created after Close must not refresh in the background")
+	}
+}
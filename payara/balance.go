@@ -2,8 +2,8 @@ package payara
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/turahe/payara-go-sdk/payara/types"
 )
@@ -12,25 +12,56 @@ const balancePath = "/api/v1/balance"
 
 // GetBalance sends GET /api/v1/balance. Doc: Get Balance
 func (s *balanceService) GetBalance(ctx context.Context) (*types.BalanceResponse, error) {
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, s.client.baseURL+balancePath, nil)
+	ctx = ContextWithOperation(ctx, OperationBalance)
+	return Do[types.BalanceData](ctx, s.client, http.MethodGet, balancePath, nil)
+}
+
+// Payout is an amount to a bank or e-wallet code, as checked by EnsureSufficient.
+type Payout struct {
+	Amount   types.IDR
+	BankCode string
+}
+
+// PayoutOf returns the Payout for req.
+func PayoutOf(req types.CreateDisbursementRequest) Payout {
+	return Payout{Amount: req.Amount, BankCode: req.BankCode}
+}
+
+// EnsureSufficient fetches the balance and checks it covers payouts plus their fees from Config.FeeSchedule.
+// It returns the balance data when it does, *InsufficientBalanceError (errors.Is ErrInsufficientBalance) with
+// the shortfall when it does not, and ErrAccountSuspended / ErrAccountBlocked when the account cannot pay out.
+// Nothing is sent to the disbursement endpoint.
+func (s *balanceService) EnsureSufficient(ctx context.Context, payouts ...Payout) (*types.BalanceData, error) {
+	var amounts, fees types.IDR
+	var err error
+	for _, p := range payouts {
+		if amounts, err = amounts.Add(p.Amount); err != nil {
+			return nil, err
+		}
+		if fees, err = fees.Add(s.client.fees.Fee(p.BankCode, p.Amount)); err != nil {
+			return nil, err
+		}
+	}
+	required, err := amounts.Add(fees)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.doRequest(ctx, httpReq)
+	resp, err := s.GetBalance(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	raw, _ := readAll(resp.Body)
-	var out types.BalanceResponse
-	if err := json.Unmarshal(raw, &out); err != nil {
-		return nil, parseErrorResponse(raw, resp.StatusCode)
+	bal := resp.Data
+	if bal == nil {
+		return nil, &APIError{Message: "balance response missing data", HTTPStatus: http.StatusOK}
 	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, parseErrorResponse(raw, resp.StatusCode)
+	switch {
+	case strings.EqualFold(string(bal.Status), string(types.AccountStatusSuspended)):
+		return bal, ErrAccountSuspended
+	case strings.EqualFold(string(bal.Status), string(types.AccountStatusBlocked)):
+		return bal, ErrAccountBlocked
 	}
-	if !out.Success {
-		return nil, parseErrorResponse(raw, resp.StatusCode)
+	if bal.Balance < required {
+		return bal, &InsufficientBalanceError{Required: required, Fees: fees, Available: bal.Balance, Shortfall: required - bal.Balance}
 	}
-	return &out, nil
+	return bal, nil
 }
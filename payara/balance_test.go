@@ -0,0 +1,83 @@
+package payara
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/turahe/payara-go-sdk/payara/internal/paytest"
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// balanceHandler reports balance and status from the balance endpoint and charges fee on disbursements.
+func balanceHandler(balance, status, fee string) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == disbursementPath {
+			return paytest.JSON(200, `{"success":true,"message":"ok","data":{"transaction_id":"T1","amount":100000,"fee":`+fee+`,"status":"PROCESS"}}`)
+		}
+		return paytest.JSON(200, `{"success":true,"message":"ok","data":{"balance":"`+balance+`","status":"`+status+`"}}`)
+	}
+}
+
+func TestEnsureSufficient_StaticFees(t *testing.T) {
+	fees := StaticFeeSchedule{Default: 2500, ByCode: map[string]types.IDR{"282": 1000}}
+	c := mockClient(&paytest.API{Handler: balanceHandler("203.000", "ACTIVE", "0")}, &Config{FeeSchedule: fees})
+
+	// 100.000 + 2.500 + 100.000 + 1.000 = 203.500 > 203.000
+	_, err := c.Balance().EnsureSufficient(context.Background(), Payout{Amount: 100000, BankCode: "5"}, Payout{Amount: 100000, BankCode: "282"})
+	var ie *InsufficientBalanceError
+	if !errors.As(err, &ie) {
+		t.Fatalf("expected InsufficientBalanceError, got %v", err)
+	}
+	if ie.Required != 203500 || ie.Fees != 3500 || ie.Available != 203000 || ie.Shortfall != 500 {
+		t.Errorf("got %+v", ie)
+	}
+	if !errors.Is(err, ErrInsufficientBalance) {
+		t.Error("expected errors.Is(err, ErrInsufficientBalance)")
+	}
+
+	bal, err := c.Balance().EnsureSufficient(context.Background(), Payout{Amount: 100000, BankCode: "282"})
+	if err != nil || bal == nil || bal.Balance != 203000 {
+		t.Errorf("sufficient: bal=%+v err=%v", bal, err)
+	}
+}
+
+func TestEnsureSufficient_LearnsFees(t *testing.T) {
+	c := mockClient(&paytest.API{Handler: balanceHandler("102000", "ACTIVE", "2500")}, nil)
+	p := Payout{Amount: 100000, BankCode: "5"}
+	if _, err := c.Balance().EnsureSufficient(context.Background(), p); err != nil {
+		t.Fatalf("before any disbursement fees are unknown: %v", err)
+	}
+	req := types.CreateDisbursementRequest{ReferenceID: "R1", Amount: 100000, BankCode: "5", AccountNumber: "12330922231", AccountName: "A"}
+	if _, err := c.Transfer().CreateDisbursement(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	_, err := c.Balance().EnsureSufficient(context.Background(), p)
+	var ie *InsufficientBalanceError
+	if !errors.As(err, &ie) || ie.Fees != 2500 || ie.Shortfall != 500 {
+		t.Errorf("expected learned 2500 fee, got %v", err)
+	}
+}
+
+func TestEnsureSufficient_AccountStatus(t *testing.T) {
+	for status, want := range map[string]error{"SUSPENDED": ErrAccountSuspended, "BLOCKED": ErrAccountBlocked} {
+		c := mockClient(&paytest.API{Handler: balanceHandler("1000000", status, "0")}, nil)
+		_, err := c.Balance().EnsureSufficient(context.Background(), Payout{Amount: 10000, BankCode: "5"})
+		if !errors.Is(err, want) {
+			t.Errorf("%s: got %v", status, err)
+		}
+	}
+}
+
+func TestLearnedFeeSchedule(t *testing.T) {
+	s := NewLearnedFeeSchedule(StaticFeeSchedule{Default: 4000})
+	if f := s.Fee("5", 100000); f != 4000 {
+		t.Errorf("fallback fee = %d", f)
+	}
+	s.ObserveFee("5", 100000, 2500)
+	s.ObserveFee("5", 100000, 2000)
+	if f := s.Fee("5", 100000); f != 2500 {
+		t.Errorf("learned fee = %d, want highest seen 2500", f)
+	}
+}
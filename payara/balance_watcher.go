@@ -0,0 +1,224 @@
+package payara
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// BalanceEventKind is the type of a BalanceEvent.
+type BalanceEventKind string
+
+const (
+	// BalanceEventLow fires when the balance drops below a threshold (or is below it on the first poll).
+	BalanceEventLow BalanceEventKind = "low"
+	// BalanceEventRecovered fires when the balance rises back to or above a threshold that fired BalanceEventLow.
+	BalanceEventRecovered BalanceEventKind = "recovered"
+	// BalanceEventStatus fires when the account status changes, or is not ACTIVE on the first poll.
+	BalanceEventStatus BalanceEventKind = "status"
+	// BalanceEventError fires when a poll fails.
+	BalanceEventError BalanceEventKind = "error"
+)
+
+// BalanceEvent is emitted by BalanceWatcher. Previous is nil for the first observation; Current is nil for errors.
+type BalanceEvent struct {
+	Kind      BalanceEventKind
+	Threshold types.IDR // For BalanceEventLow / BalanceEventRecovered
+	Previous  *types.BalanceData
+	Current   *types.BalanceData
+	Err       error // For BalanceEventError
+	At        time.Time
+}
+
+// BalanceWatcherOptions configures a BalanceWatcher. Zero values use the defaults.
+type BalanceWatcherOptions struct {
+	Interval   time.Duration // Poll interval (default 30s)
+	TTL        time.Duration // How long Balance serves the cached value (default Interval)
+	Thresholds []types.IDR   // Low-balance thresholds; each fires once per crossing
+	// OnEvent, if set, is called for every event from the goroutine that observed it. Keep it fast.
+	OnEvent func(BalanceEvent)
+	// EventBuffer is the capacity of the Events channel (default 16). Events are dropped when it is full.
+	EventBuffer int
+}
+
+// BalanceWatcher polls GetBalance, serves a cached balance to readers, and reports low-balance and
+// account-status changes so operators hear about them before payouts start failing.
+// Create it with NewBalanceWatcher and run it with Run.
+type BalanceWatcher struct {
+	svc        BalanceService
+	interval   time.Duration
+	ttl        time.Duration
+	thresholds []types.IDR // ascending
+	onEvent    func(BalanceEvent)
+	events     chan BalanceEvent
+	now        func() time.Time
+
+	fetchMu sync.Mutex // one GetBalance at a time
+
+	eventsMu sync.Mutex // guards sends against close of events
+	closed   bool
+
+	mu        sync.RWMutex
+	current   *types.BalanceData
+	fetchedAt time.Time
+	low       map[types.IDR]bool // thresholds currently below
+}
+
+// NewBalanceWatcher returns a watcher over svc (e.g. client.Balance()).
+func NewBalanceWatcher(svc BalanceService, opts BalanceWatcherOptions) *BalanceWatcher {
+	if opts.Interval <= 0 {
+		opts.Interval = 30 * time.Second
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = opts.Interval
+	}
+	if opts.EventBuffer <= 0 {
+		opts.EventBuffer = 16
+	}
+	thresholds := append([]types.IDR(nil), opts.Thresholds...)
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i] < thresholds[j] })
+	return &BalanceWatcher{
+		svc:        svc,
+		interval:   opts.Interval,
+		ttl:        opts.TTL,
+		thresholds: thresholds,
+		onEvent:    opts.OnEvent,
+		events:     make(chan BalanceEvent, opts.EventBuffer),
+		now:        time.Now,
+		low:        make(map[types.IDR]bool),
+	}
+}
+
+// Events returns the event channel. It is closed when Run returns.
+func (w *BalanceWatcher) Events() <-chan BalanceEvent { return w.events }
+
+// Run polls until ctx is done, then closes Events and returns ctx.Err(). The first poll happens immediately.
+// Call it once, typically with go.
+func (w *BalanceWatcher) Run(ctx context.Context) error {
+	defer func() {
+		w.eventsMu.Lock()
+		w.closed = true
+		close(w.events)
+		w.eventsMu.Unlock()
+	}()
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+	for {
+		_, _ = w.refresh(ctx)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Balance returns the cached balance if it is younger than TTL, otherwise fetches it.
+// Concurrent callers share one fetch.
+func (w *BalanceWatcher) Balance(ctx context.Context) (*types.BalanceData, error) {
+	if b, ok := w.fresh(); ok {
+		return b, nil
+	}
+	w.fetchMu.Lock()
+	defer w.fetchMu.Unlock()
+	if b, ok := w.fresh(); ok {
+		return b, nil
+	}
+	return w.fetchLocked(ctx)
+}
+
+// Cached returns the last fetched balance and when it was fetched, without any network call.
+// ok is false before the first successful poll.
+func (w *BalanceWatcher) Cached() (b *types.BalanceData, at time.Time, ok bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.current == nil {
+		return nil, time.Time{}, false
+	}
+	c := *w.current
+	return &c, w.fetchedAt, true
+}
+
+func (w *BalanceWatcher) fresh() (*types.BalanceData, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.current == nil || w.now().Sub(w.fetchedAt) >= w.ttl {
+		return nil, false
+	}
+	c := *w.current
+	return &c, true
+}
+
+func (w *BalanceWatcher) refresh(ctx context.Context) (*types.BalanceData, error) {
+	w.fetchMu.Lock()
+	defer w.fetchMu.Unlock()
+	return w.fetchLocked(ctx)
+}
+
+// fetchLocked calls GetBalance, updates the cache and emits events. Call with fetchMu held.
+func (w *BalanceWatcher) fetchLocked(ctx context.Context) (*types.BalanceData, error) {
+	resp, err := w.svc.GetBalance(ctx)
+	if err == nil && resp.Data == nil {
+		err = &APIError{Message: "balance response missing data", HTTPStatus: 200}
+	}
+	at := w.now()
+	if err != nil {
+		if ctx.Err() == nil {
+			w.emit(BalanceEvent{Kind: BalanceEventError, Err: err, At: at})
+		}
+		return nil, err
+	}
+	cur := *resp.Data
+	evCur := cur // events get their own copy so handlers cannot mutate the cache
+
+	w.mu.Lock()
+	prev := w.current
+	if prev != nil {
+		p := *prev
+		prev = &p
+	}
+	w.current, w.fetchedAt = &cur, at
+	var events []BalanceEvent
+	if prev == nil && !strings.EqualFold(string(cur.Status), string(types.AccountStatusActive)) ||
+		prev != nil && !strings.EqualFold(string(prev.Status), string(cur.Status)) {
+		events = append(events, BalanceEvent{Kind: BalanceEventStatus, Previous: prev, Current: &evCur, At: at})
+	}
+	for _, th := range w.thresholds {
+		below := cur.Balance < th
+		switch {
+		case below && !w.low[th]:
+			w.low[th] = true
+			events = append(events, BalanceEvent{Kind: BalanceEventLow, Threshold: th, Previous: prev, Current: &evCur, At: at})
+		case !below && w.low[th]:
+			delete(w.low, th)
+			events = append(events, BalanceEvent{Kind: BalanceEventRecovered, Threshold: th, Previous: prev, Current: &evCur, At: at})
+		}
+	}
+	w.mu.Unlock()
+
+	for _, ev := range events {
+		w.emit(ev)
+	}
+	c := cur
+	return &c, nil
+}
+
+// emit delivers ev to OnEvent and, without blocking, to the Events channel.
+func (w *BalanceWatcher) emit(ev BalanceEvent) {
+	if w.onEvent != nil {
+		w.onEvent(ev)
+	}
+	w.eventsMu.Lock()
+	defer w.eventsMu.Unlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.events <- ev:
+	default:
+	}
+}
@@ -0,0 +1,160 @@
+package payara
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// scriptedBalance returns the next scripted balance on each GetBalance call (repeating the last one).
+type scriptedBalance struct {
+	BalanceService
+	mu     sync.Mutex
+	script []types.BalanceData
+	errAt  int // 1-based call that fails; 0 for none
+	calls  int32
+}
+
+func (s *scriptedBalance) GetBalance(context.Context) (*types.BalanceResponse, error) {
+	n := int(atomic.AddInt32(&s.calls, 1))
+	if n == s.errAt {
+		return nil, &APIError{Code: "SERVER_ERROR", HTTPStatus: 503}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := n - 1
+	if s.errAt > 0 && n > s.errAt {
+		i--
+	}
+	if i >= len(s.script) {
+		i = len(s.script) - 1
+	}
+	d := s.script[i]
+	return &types.BalanceResponse{Success: true, Data: &d}, nil
+}
+
+func bal(amount types.IDR, status types.AccountStatus) types.BalanceData {
+	return types.BalanceData{Balance: amount, Status: status}
+}
+
+func TestBalanceWatcher_ThresholdsAndStatus(t *testing.T) {
+	svc := &scriptedBalance{script: []types.BalanceData{
+		bal(5_000_000, types.AccountStatusActive),
+		bal(800_000, types.AccountStatusActive),   // below 1M
+		bal(90_000, types.AccountStatusActive),    // below 100k too
+		bal(2_000_000, types.AccountStatusActive), // recovered both
+		bal(2_000_000, types.AccountStatusSuspended),
+	}}
+	var got []BalanceEvent
+	w := NewBalanceWatcher(svc, BalanceWatcherOptions{
+		Interval:   time.Hour,
+		Thresholds: []types.IDR{1_000_000, 100_000},
+		OnEvent:    func(ev BalanceEvent) { got = append(got, ev) },
+	})
+	for i := 0; i < 5; i++ {
+		if _, err := w.refresh(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	type ev struct {
+		kind BalanceEventKind
+		th   types.IDR
+	}
+	want := []ev{
+		{BalanceEventLow, 1_000_000},
+		{BalanceEventLow, 100_000},
+		{BalanceEventRecovered, 100_000},
+		{BalanceEventRecovered, 1_000_000},
+		{BalanceEventStatus, 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("events = %+v", got)
+	}
+	for i, e := range want {
+		if got[i].Kind != e.kind || got[i].Threshold != e.th {
+			t.Errorf("event %d = %s/%d, want %s/%d", i, got[i].Kind, got[i].Threshold, e.kind, e.th)
+		}
+	}
+	last := got[4]
+	if last.Previous.Status != types.AccountStatusActive || last.Current.Status != types.AccountStatusSuspended {
+		t.Errorf("status event = %+v -> %+v", last.Previous, last.Current)
+	}
+	// The channel saw the same events.
+	if n := len(w.Events()); n != 5 {
+		t.Errorf("channel has %d events", n)
+	}
+}
+
+func TestBalanceWatcher_FirstPollNotActive(t *testing.T) {
+	svc := &scriptedBalance{script: []types.BalanceData{bal(10, types.AccountStatusBlocked)}}
+	w := NewBalanceWatcher(svc, BalanceWatcherOptions{Interval: time.Hour})
+	_, _ = w.refresh(context.Background())
+	ev := <-w.Events()
+	if ev.Kind != BalanceEventStatus || ev.Previous != nil || ev.Current.Status != types.AccountStatusBlocked {
+		t.Errorf("got %+v", ev)
+	}
+}
+
+func TestBalanceWatcher_CacheTTL(t *testing.T) {
+	svc := &scriptedBalance{script: []types.BalanceData{bal(100, types.AccountStatusActive), bal(200, types.AccountStatusActive)}}
+	now := time.Unix(0, 0)
+	w := NewBalanceWatcher(svc, BalanceWatcherOptions{Interval: time.Minute, TTL: 10 * time.Second})
+	w.now = func() time.Time { return now }
+
+	if _, _, ok := w.Cached(); ok {
+		t.Error("Cached before first fetch")
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if b, err := w.Balance(context.Background()); err != nil || b.Balance != 100 {
+				t.Errorf("Balance = %+v, %v", b, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if svc.calls != 1 {
+		t.Errorf("concurrent readers made %d calls, want 1", svc.calls)
+	}
+	now = now.Add(11 * time.Second)
+	if b, _ := w.Balance(context.Background()); b.Balance != 200 || svc.calls != 2 {
+		t.Errorf("after TTL: balance %d, calls %d", b.Balance, svc.calls)
+	}
+	if b, at, ok := w.Cached(); !ok || b.Balance != 200 || !at.Equal(now) {
+		t.Errorf("Cached = %+v %v %v", b, at, ok)
+	}
+}
+
+func TestBalanceWatcher_RunAndErrors(t *testing.T) {
+	svc := &scriptedBalance{script: []types.BalanceData{bal(100, types.AccountStatusActive)}, errAt: 2}
+	w := NewBalanceWatcher(svc, BalanceWatcherOptions{Interval: 5 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	var sawError bool
+	for ev := range w.Events() {
+		if ev.Kind == BalanceEventError {
+			sawError = errors.Is(ev.Err, ErrServer)
+			cancel()
+		}
+	}
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run returned %v", err)
+	}
+	if !sawError {
+		t.Error("expected an error event matching ErrServer")
+	}
+	// Readers keep working after Run stops.
+	if _, err := w.Balance(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
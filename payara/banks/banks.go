@@ -0,0 +1,327 @@
+// Package banks is a registry of Payara disbursement destinations (banks and e-wallets):
+// bank_code, display name, type and account-number format rules. Use it for UI dropdowns and
+// to validate CreateDisbursementRequest against the destinations your merchant supports.
+package banks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/turahe/payara-go-sdk/payara"
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// Type is the destination kind.
+type Type string
+
+const (
+	TypeBank    Type = "bank"
+	TypeEWallet Type = "ewallet"
+)
+
+// Destination is one supported bank or e-wallet.
+type Destination struct {
+	Code      string   `json:"code"`               // bank_code sent to Payara
+	Name      string   `json:"name"`               // Display name
+	Type      Type     `json:"type"`               // bank | ewallet
+	MinLength int      `json:"min_length"`         // Min account_number digits (0 = type default)
+	MaxLength int      `json:"max_length"`         // Max account_number digits (0 = type default)
+	Prefixes  []string `json:"prefixes,omitempty"` // Allowed account_number prefixes (empty = any; e-wallet default 08, 628)
+}
+
+// UnmarshalJSON accepts both {"code","name"} and Payara-style {"bank_code","bank_name"} keys.
+func (d *Destination) UnmarshalJSON(data []byte) error {
+	type plain Destination
+	var aux struct {
+		plain
+		BankCode string `json:"bank_code"`
+		BankName string `json:"bank_name"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*d = Destination(aux.plain)
+	if d.Code == "" {
+		d.Code = aux.BankCode
+	}
+	if d.Name == "" {
+		d.Name = aux.BankName
+	}
+	return nil
+}
+
+// limits returns the effective account-number rules for d.
+func (d Destination) limits() (min, max int, prefixes []string) {
+	min, max, prefixes = 5, 20, d.Prefixes
+	if d.Type == TypeEWallet {
+		min, max = 10, 14
+		if len(prefixes) == 0 {
+			prefixes = []string{"08", "628"}
+		}
+	}
+	if d.MinLength > 0 {
+		min = d.MinLength
+	}
+	if d.MaxLength > 0 {
+		max = d.MaxLength
+	}
+	return min, max, prefixes
+}
+
+// ValidateAccountNumber checks accountNumber against d's format rules.
+func (d Destination) ValidateAccountNumber(accountNumber string) error {
+	min, max, prefixes := d.limits()
+	if accountNumber == "" {
+		return fmt.Errorf("banks: account number is required")
+	}
+	for _, r := range accountNumber {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("banks: %s account number must contain digits only", d.Name)
+		}
+	}
+	if len(accountNumber) < min || len(accountNumber) > max {
+		return fmt.Errorf("banks: %s account number must be %d-%d digits", d.Name, min, max)
+	}
+	if len(prefixes) == 0 {
+		return nil
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(accountNumber, p) {
+			return nil
+		}
+	}
+	return fmt.Errorf("banks: %s account number must start with %s", d.Name, strings.Join(prefixes, " or "))
+}
+
+// DefaultDestinations are the destinations documented in the Payara sandbox data
+// (https://doc.payara.id/docs/1.0/sandbox-data-dummy), derived from payara.SandboxDummyAccounts and
+// types.EWalletBankCodes so the lists cannot drift apart. Load or refresh the full production list
+// for your merchant with LoadFile / LoadJSON / Registry.Refresh.
+var DefaultDestinations = sandboxDestinations()
+
+func sandboxDestinations() []Destination {
+	var ds []Destination
+	seen := make(map[string]bool)
+	for _, a := range payara.SandboxDummyAccounts {
+		if seen[a.BankCode] {
+			continue
+		}
+		seen[a.BankCode] = true
+		t := TypeBank
+		if types.EWalletBankCodes[a.BankCode] {
+			t = TypeEWallet
+		}
+		ds = append(ds, Destination{Code: a.BankCode, Name: a.BankName, Type: t})
+	}
+	return ds
+}
+
+// Registry is a concurrency-safe set of destinations indexed by code and name.
+type Registry struct {
+	mu     sync.RWMutex
+	list   []Destination // Sorted by name
+	byCode map[string]Destination
+	byName map[string]Destination // Lower-cased name
+}
+
+// NewRegistry creates a registry from ds. Later entries with a duplicate code replace earlier ones.
+func NewRegistry(ds []Destination) *Registry {
+	r := &Registry{}
+	r.Replace(ds)
+	return r
+}
+
+// Default returns a registry of DefaultDestinations.
+func Default() *Registry {
+	return NewRegistry(DefaultDestinations)
+}
+
+// Replace swaps the registry contents for ds.
+func (r *Registry) Replace(ds []Destination) {
+	byCode := make(map[string]Destination, len(ds))
+	for _, d := range ds {
+		byCode[d.Code] = d
+	}
+	list := make([]Destination, 0, len(byCode))
+	byName := make(map[string]Destination, len(byCode))
+	for _, d := range byCode {
+		list = append(list, d)
+		byName[strings.ToLower(d.Name)] = d
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	r.mu.Lock()
+	r.list, r.byCode, r.byName = list, byCode, byName
+	r.mu.Unlock()
+}
+
+// Lookup returns the destination for bank_code.
+func (r *Registry) Lookup(code string) (Destination, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.byCode[code]
+	return d, ok
+}
+
+// LookupByName returns the destination with the given display name (case-insensitive).
+func (r *Registry) LookupByName(name string) (Destination, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.byName[strings.ToLower(strings.TrimSpace(name))]
+	return d, ok
+}
+
+// Search returns destinations whose name contains query (case-insensitive), sorted by name.
+func (r *Registry) Search(query string) []Destination {
+	q := strings.ToLower(strings.TrimSpace(query))
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []Destination
+	for _, d := range r.list {
+		if strings.Contains(strings.ToLower(d.Name), q) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// All returns every destination sorted by name (e.g. for a dropdown).
+func (r *Registry) All() []Destination {
+	return r.filter("")
+}
+
+// Banks returns bank destinations sorted by name.
+func (r *Registry) Banks() []Destination { return r.filter(TypeBank) }
+
+// EWallets returns e-wallet destinations sorted by name.
+func (r *Registry) EWallets() []Destination { return r.filter(TypeEWallet) }
+
+func (r *Registry) filter(t Type) []Destination {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Destination, 0, len(r.list))
+	for _, d := range r.list {
+		if t == "" || d.Type == t {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// ValidateAccount checks that code is a known destination and accountNumber matches its rules.
+func (r *Registry) ValidateAccount(code, accountNumber string) error {
+	d, ok := r.Lookup(code)
+	if !ok {
+		return fmt.Errorf("banks: unknown bank_code %q", code)
+	}
+	return d.ValidateAccountNumber(accountNumber)
+}
+
+// CheckDestination implements types.DestinationChecker: bank_code must be in the registry and
+// account_number must match that destination's rules. Set the registry as Config.Destinations so
+// CreateDisbursement validates against it.
+func (r *Registry) CheckDestination(code, accountNumber string) []types.FieldError {
+	d, ok := r.Lookup(code)
+	if !ok {
+		return []types.FieldError{{Field: "bank_code", Message: "is not a supported destination"}}
+	}
+	if err := d.ValidateAccountNumber(accountNumber); err != nil {
+		return []types.FieldError{{Field: "account_number", Message: strings.TrimPrefix(err.Error(), "banks: ")}}
+	}
+	return nil
+}
+
+// ValidateRequest is req.ValidateWith(r): the request rules plus registry checks (known bank_code,
+// per-destination account-number rules). Returns *types.ValidationError or nil.
+func (r *Registry) ValidateRequest(req types.CreateDisbursementRequest) error {
+	return req.ValidateWith(r)
+}
+
+// LoadJSON reads destinations from r. It accepts a JSON array or a Payara envelope {"success":..,"data":[..]}.
+// An empty list, an entry without a code or a type other than bank/ewallet is an error.
+func LoadJSON(rd io.Reader) (*Registry, error) {
+	ds, err := decodeDestinations(rd)
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistry(ds), nil
+}
+
+// LoadFile reads destinations from a JSON file (see LoadJSON).
+func LoadFile(path string) (*Registry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadJSON(f)
+}
+
+func decodeDestinations(rd io.Reader) ([]Destination, error) {
+	raw, err := io.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+	var ds []Destination
+	if err := json.Unmarshal(raw, &ds); err != nil {
+		var env struct {
+			Success bool          `json:"success"`
+			Message string        `json:"message"`
+			Data    []Destination `json:"data"`
+		}
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return nil, fmt.Errorf("banks: decode destinations: %w", err)
+		}
+		ds = env.Data
+	}
+	if err := checkDestinations(ds); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// errNoDestinations is returned for an empty list: as Config.Destinations it would reject every disbursement.
+var errNoDestinations = errors.New("banks: no destinations")
+
+// checkDestinations rejects an empty list and entries without a code or with an unknown type.
+func checkDestinations(ds []Destination) error {
+	if len(ds) == 0 {
+		return errNoDestinations
+	}
+	for i, d := range ds {
+		if d.Code == "" {
+			return fmt.Errorf("banks: destination %d has no code", i)
+		}
+		if d.Type != TypeBank && d.Type != TypeEWallet {
+			return fmt.Errorf("banks: destination %s has unknown type %q (want %q or %q)", d.Code, d.Type, TypeBank, TypeEWallet)
+		}
+	}
+	return nil
+}
+
+// Refresh replaces the registry contents with the list served at path (e.g. a bank-list endpoint) on
+// client's base URL, using payara.Do. The response must be a Payara envelope whose data is the list;
+// success=false or a non-2xx status is returned as the *payara.APIError, and the list is checked as in LoadJSON.
+// On error the current contents are kept.
+func (r *Registry) Refresh(ctx context.Context, client *payara.Client, path string) error {
+	resp, err := payara.Do[[]Destination](ctx, client, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	var ds []Destination
+	if resp.Data != nil {
+		ds = *resp.Data
+	}
+	if err := checkDestinations(ds); err != nil {
+		return fmt.Errorf("banks: refresh from %s: %w", path, err)
+	}
+	r.Replace(ds)
+	return nil
+}
@@ -0,0 +1,166 @@
+package banks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/turahe/payara-go-sdk/payara"
+	"github.com/turahe/payara-go-sdk/payara/internal/paytest"
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+func TestRegistry_Lookup(t *testing.T) {
+	r := Default()
+	d, ok := r.Lookup("5")
+	if !ok || d.Name != "Bank Central Asia" || d.Type != TypeBank {
+		t.Errorf("Lookup(5): %+v %v", d, ok)
+	}
+	d, ok = r.LookupByName("dana")
+	if !ok || d.Code != "282" || d.Type != TypeEWallet {
+		t.Errorf("LookupByName(dana): %+v %v", d, ok)
+	}
+	if _, ok := r.Lookup("999"); ok {
+		t.Error("Lookup(999) should miss")
+	}
+	if got := r.Search("bank"); len(got) != 3 {
+		t.Errorf("Search(bank): got %d", len(got))
+	}
+	all := r.All()
+	if len(all) != len(DefaultDestinations) || all[0].Name != "Bank Central Asia" {
+		t.Errorf("All: %+v", all)
+	}
+	if len(r.Banks()) != 3 || len(r.EWallets()) != 3 {
+		t.Errorf("Banks=%d EWallets=%d", len(r.Banks()), len(r.EWallets()))
+	}
+}
+
+func TestDestination_ValidateAccountNumber(t *testing.T) {
+	bank := Destination{Code: "5", Name: "BCA", Type: TypeBank, MinLength: 10, MaxLength: 10}
+	if err := bank.ValidateAccountNumber("1233092223"); err != nil {
+		t.Errorf("bank: %v", err)
+	}
+	if err := bank.ValidateAccountNumber("123309222"); err == nil {
+		t.Error("bank: expected length error")
+	}
+	ewallet := Destination{Code: "281", Name: "OVO", Type: TypeEWallet}
+	if err := ewallet.ValidateAccountNumber("081212239281"); err != nil {
+		t.Errorf("ewallet: %v", err)
+	}
+	if err := ewallet.ValidateAccountNumber("071212239281"); err == nil {
+		t.Error("ewallet: expected prefix error")
+	}
+}
+
+func TestRegistry_ValidateRequest(t *testing.T) {
+	r := NewRegistry([]Destination{{Code: "5", Name: "BCA", Type: TypeBank, MinLength: 10, MaxLength: 10}})
+	ok := types.CreateDisbursementRequest{ReferenceID: "R", Amount: 10000, BankCode: "5", AccountNumber: "1233092223", AccountName: "A"}
+	if err := r.ValidateRequest(ok); err != nil {
+		t.Fatal(err)
+	}
+	bad := ok
+	bad.AccountNumber = "12330922231" // valid for a generic bank, but BCA here requires 10 digits
+	var ve *types.ValidationError
+	if err := r.ValidateRequest(bad); !errors.As(err, &ve) || len(ve.Field("account_number")) != 1 {
+		t.Errorf("BCA length: got %v", err)
+	}
+	unknown := ok
+	unknown.BankCode = "7"
+	unknown.Amount = 1
+	if err := r.ValidateRequest(unknown); !errors.As(err, &ve) || len(ve.Field("bank_code")) != 1 || len(ve.Field("amount")) != 1 {
+		t.Errorf("unknown bank: got %v", err)
+	}
+}
+
+func TestLoadJSON_ArrayAndEnvelope(t *testing.T) {
+	arr := `[{"code":"14","name":"Bank BRI","type":"bank"},{"bank_code":"290","bank_name":"ShopeePay","type":"ewallet"}]`
+	r, err := LoadJSON(strings.NewReader(arr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := r.Lookup("290"); !ok || d.Name != "ShopeePay" {
+		t.Errorf("alias keys: %+v %v", d, ok)
+	}
+	env := `{"success":true,"message":"ok","data":[{"code":"14","name":"Bank BRI","type":"bank"}]}`
+	r, err = LoadJSON(strings.NewReader(env))
+	if err != nil || len(r.All()) != 1 {
+		t.Fatalf("envelope: %v %v", r, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "banks.json")
+	if err := os.WriteFile(path, []byte(arr), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err = LoadFile(path)
+	if err != nil || len(r.All()) != 2 {
+		t.Fatalf("LoadFile: %v %v", r, err)
+	}
+}
+
+func TestLoadJSON_RejectsEmptyAndUnknownType(t *testing.T) {
+	for _, in := range []string{`[]`, `{}`, `{"data":null}`, `{"success":true,"data":[]}`} {
+		if _, err := LoadJSON(strings.NewReader(in)); !errors.Is(err, errNoDestinations) {
+			t.Errorf("LoadJSON(%s): got %v, want errNoDestinations", in, err)
+		}
+	}
+	for _, in := range []string{
+		`[{"code":"14","name":"Bank BRI","type":"wallet"}]`,
+		`[{"code":"14","name":"Bank BRI"}]`,
+		`[{"name":"Bank BRI","type":"bank"}]`,
+	} {
+		if _, err := LoadJSON(strings.NewReader(in)); err == nil {
+			t.Errorf("LoadJSON(%s): expected error", in)
+		}
+	}
+}
+
+func TestRegistry_Refresh(t *testing.T) {
+	body := `{"success":true,"message":"ok","data":[{"bank_code":"14","bank_name":"Bank BRI","type":"bank"}]}`
+	api := &paytest.API{Handler: func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/api/v1/bank-list" || req.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("unexpected request %s (auth %q)", req.URL.Path, req.Header.Get("Authorization"))
+		}
+		return paytest.JSON(200, body)
+	}}
+	r := Default()
+	client := payara.NewClient(&payara.Config{AppID: "a", AppSecret: "b", BaseURL: "https://test.payara.id",
+		HTTPClient: &http.Client{Transport: api}, Destinations: r})
+	req := types.CreateDisbursementRequest{ReferenceID: "R1", Amount: 10000, BankCode: "14", AccountNumber: "12330922231", AccountName: "A"}
+	var ve *types.ValidationError
+	if _, err := client.Transfer().CreateDisbursement(context.Background(), req); !errors.As(err, &ve) || len(ve.Field("bank_code")) != 1 {
+		t.Fatalf("before refresh bank 14 is unknown: %v", err)
+	}
+
+	if err := r.Refresh(context.Background(), client, "/api/v1/bank-list"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.Lookup("14"); !ok || len(r.All()) != 1 {
+		t.Errorf("after refresh: %+v", r.All())
+	}
+	if err := r.ValidateRequest(req); err != nil {
+		t.Errorf("after refresh bank 14 is supported: %v", err)
+	}
+
+	body = `{"success":false,"message":"merchant has no bank list","error_code":"NOT_CONFIGURED"}`
+	err := r.Refresh(context.Background(), client, "/api/v1/bank-list")
+	var apiErr *payara.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "merchant has no bank list" {
+		t.Errorf("success=false: got %v", err)
+	}
+	if len(r.All()) != 1 {
+		t.Error("failed refresh must keep the current contents")
+	}
+}
+
+func TestDefaultDestinations_MatchSandbox(t *testing.T) {
+	for _, a := range payara.SandboxDummyAccounts {
+		d, ok := Default().Lookup(a.BankCode)
+		if !ok || d.Name != a.BankName || (d.Type == TypeEWallet) != types.EWalletBankCodes[a.BankCode] {
+			t.Errorf("%s: %+v %v", a.BankCode, d, ok)
+		}
+	}
+}
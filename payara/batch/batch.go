@@ -0,0 +1,403 @@
+// Package batch runs many disbursements (e.g. payroll) through a TransferService with bounded concurrency,
+// rate limiting, an up-front balance check, streamed per-item results and resume support.
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara"
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// ItemStatus is the outcome of one batch item.
+type ItemStatus string
+
+const (
+	// StatusSucceeded means Payara reported SUCCESS.
+	StatusSucceeded ItemStatus = "succeeded"
+	// StatusFailed means the item was rejected or Payara reported FAILED; it did not pay out.
+	StatusFailed ItemStatus = "failed"
+	// StatusPending means Payara reported PROCESS, or the outcome is unknown (Err is *payara.DisbursementUnknownError).
+	// Reconcile by ReferenceID; do not resubmit blindly.
+	StatusPending ItemStatus = "pending"
+	// StatusSkipped means the Journal shows the item was submitted by an earlier run.
+	StatusSkipped ItemStatus = "skipped"
+)
+
+// Result is the outcome of one item. Index is the item's position in the input (receive order for channels).
+type Result struct {
+	Index    int
+	Request  types.CreateDisbursementRequest
+	Status   ItemStatus
+	Response *types.CreateDisbursementResponseData // nil unless Payara accepted the request
+	Err      error                                 // *ItemError for failed items; set for unknown-outcome pending items
+}
+
+// Summary totals a run. TotalAmount and TotalFees cover succeeded and pending items with a response.
+type Summary struct {
+	Total       int
+	Succeeded   int
+	Failed      int
+	Pending     int
+	Skipped     int
+	TotalAmount types.IDR
+	TotalFees   types.IDR
+	Duration    time.Duration
+}
+
+// add counts r into s. Called from a single goroutine.
+func (s *Summary) add(r Result) {
+	s.Total++
+	switch r.Status {
+	case StatusSucceeded:
+		s.Succeeded++
+	case StatusFailed:
+		s.Failed++
+	case StatusPending:
+		s.Pending++
+	case StatusSkipped:
+		s.Skipped++
+	}
+	if r.Response != nil && r.Status != StatusFailed {
+		// Totals are reporting only; an overflowing addition is dropped rather than failing the run.
+		if v, err := s.TotalAmount.Add(r.Response.Amount); err == nil {
+			s.TotalAmount = v
+		}
+		if v, err := s.TotalFees.Add(r.Response.Fee); err == nil {
+			s.TotalFees = v
+		}
+	}
+}
+
+// ItemError wraps the error of a failed item with its position and reference_id.
+// errors.Is / errors.As see the underlying error (e.g. payara.ErrInsufficientBalance, *types.ValidationError).
+type ItemError struct {
+	Index       int
+	ReferenceID string
+	Err         error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("batch: item %d (%s): %v", e.Index, e.ReferenceID, e.Err)
+}
+
+func (e *ItemError) Unwrap() error { return e.Err }
+
+// FailedError is the ItemError cause when Payara reported the disbursement FAILED. Reason is Payara's
+// failure_reason, empty if Payara gave none.
+type FailedError struct {
+	TransactionID string
+	Reason        string
+}
+
+func (e *FailedError) Error() string {
+	if e.Reason == "" {
+		return "disbursement FAILED"
+	}
+	return "disbursement FAILED: " + e.Reason
+}
+
+// ErrDuplicateInBatch is the ItemError cause for a reference_id that already appeared earlier in the same run.
+var ErrDuplicateInBatch = errors.New("batch: reference_id repeated within batch")
+
+// Options configures a Runner. Zero values use the defaults.
+type Options struct {
+	// Concurrency is the number of disbursements in flight at once (default 4).
+	Concurrency int
+	// RatePerSecond limits how many disbursements are started per second; 0 means unlimited.
+	RatePerSecond float64
+	// Burst is how many starts may happen back to back under RatePerSecond (default 1).
+	Burst int
+	// SkipBalanceCheck disables the up-front balance check in Run.
+	SkipBalanceCheck bool
+	// Journal records submitted items so a rerun skips them. Nil disables resume.
+	Journal Journal
+	// Safe configures CreateDisbursementSafely, which every item goes through.
+	Safe *payara.SafeDisbursementOptions
+	// MaxRateLimitWaits is how many times an item waits out a 429 before failing (default 3).
+	MaxRateLimitWaits int
+}
+
+// Runner executes batches. Create it with New; it is safe to reuse for several runs.
+type Runner struct {
+	transfers payara.TransferService
+	balance   payara.BalanceService
+	opts      Options
+}
+
+// New returns a Runner using client's services.
+func New(client *payara.Client, opts Options) *Runner {
+	return NewWithServices(client.Transfer(), client.Balance(), opts)
+}
+
+// NewWithServices returns a Runner over the given services (useful with fakes in tests).
+// balance may be nil when SkipBalanceCheck is set.
+func NewWithServices(transfers payara.TransferService, balance payara.BalanceService, opts Options) *Runner {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = 1
+	}
+	if opts.MaxRateLimitWaits <= 0 {
+		opts.MaxRateLimitWaits = 3
+	}
+	return &Runner{transfers: transfers, balance: balance, opts: opts}
+}
+
+// Run checks the balance covers reqs plus fees with BalanceService.EnsureSufficient (unless SkipBalanceCheck;
+// a shortfall is a *payara.InsufficientBalanceError), then submits them. onResult, if set, is called
+// for every item as it completes, from one goroutine at a time. The Summary is returned even when ctx is
+// cancelled mid-run (with ctx.Err()); items not started by then are not reported.
+func (r *Runner) Run(ctx context.Context, reqs []types.CreateDisbursementRequest, onResult func(Result)) (*Summary, error) {
+	if !r.opts.SkipBalanceCheck {
+		if err := r.checkBalance(ctx, reqs); err != nil {
+			return nil, err
+		}
+	}
+	in := make(chan types.CreateDisbursementRequest)
+	go func() {
+		defer close(in)
+		for _, req := range reqs {
+			select {
+			case in <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return r.RunChan(ctx, in, onResult)
+}
+
+// RunChan submits requests from in until it is closed. No balance check is done, since the total is not
+// known up front. onResult and the returned values behave as for Run.
+func (r *Runner) RunChan(ctx context.Context, in <-chan types.CreateDisbursementRequest, onResult func(Result)) (*Summary, error) {
+	start := time.Now()
+	lim := newLimiter(r.opts.RatePerSecond, r.opts.Burst)
+	defer lim.stop()
+
+	type job struct {
+		index int
+		req   types.CreateDisbursementRequest
+	}
+	jobs := make(chan job)
+	results := make(chan Result)
+	var wg sync.WaitGroup
+	for i := 0; i < r.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results <- r.process(ctx, j.index, j.req)
+			}
+		}()
+	}
+
+	// Dispatcher: assigns indexes, skips journaled items, rejects in-batch duplicates, applies the rate limit.
+	go func() {
+		defer func() {
+			close(jobs)
+			wg.Wait()
+			close(results)
+		}()
+		seen := make(map[string]bool)
+		for index := 0; ; index++ {
+			var req types.CreateDisbursementRequest
+			var ok bool
+			select {
+			case req, ok = <-in:
+			case <-ctx.Done():
+				return
+			}
+			if !ok {
+				return
+			}
+			if seen[req.ReferenceID] {
+				results <- Result{Index: index, Request: req, Status: StatusFailed,
+					Err: &ItemError{Index: index, ReferenceID: req.ReferenceID, Err: ErrDuplicateInBatch}}
+				continue
+			}
+			seen[req.ReferenceID] = true
+			if r.opts.Journal != nil {
+				done, err := r.opts.Journal.Submitted(ctx, req.ReferenceID)
+				if err != nil {
+					results <- Result{Index: index, Request: req, Status: StatusFailed,
+						Err: &ItemError{Index: index, ReferenceID: req.ReferenceID, Err: err}}
+					continue
+				}
+				if done {
+					results <- Result{Index: index, Request: req, Status: StatusSkipped}
+					continue
+				}
+			}
+			if err := lim.wait(ctx); err != nil {
+				return
+			}
+			select {
+			case jobs <- job{index: index, req: req}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	sum := &Summary{}
+	for res := range results {
+		sum.add(res)
+		if onResult != nil {
+			onResult(res)
+		}
+	}
+	sum.Duration = time.Since(start)
+	return sum, ctx.Err()
+}
+
+// process submits one item and records it in the journal when Payara may have accepted it.
+func (r *Runner) process(ctx context.Context, index int, req types.CreateDisbursementRequest) Result {
+	res := Result{Index: index, Request: req}
+	var resp *types.CreateDisbursementResponse
+	var err error
+	for waits := 0; ; waits++ {
+		resp, err = r.transfers.CreateDisbursementSafely(ctx, req, r.opts.Safe)
+		var rl *payara.RateLimitError
+		if err == nil || !errors.As(err, &rl) || waits >= r.opts.MaxRateLimitWaits {
+			break
+		}
+		d := rl.RetryAfter
+		if d <= 0 {
+			d = time.Second
+		}
+		if sleepErr := sleep(ctx, d); sleepErr != nil {
+			break
+		}
+	}
+
+	var unknown *payara.DisbursementUnknownError
+	switch {
+	case err == nil && resp.Data != nil:
+		res.Response = resp.Data
+		switch {
+		case !resp.Data.Status.IsFinal():
+			res.Status = StatusPending
+		case resp.Data.Status == types.DisbursementStatusFailed:
+			res.Status = StatusFailed
+			res.Err = &ItemError{Index: index, ReferenceID: req.ReferenceID, Err: r.failed(ctx, resp.Data)}
+		default:
+			res.Status = StatusSucceeded
+		}
+	case errors.As(err, &unknown):
+		res.Status, res.Err = StatusPending, err
+	default:
+		if err == nil {
+			err = errors.New("response missing data")
+		}
+		res.Status = StatusFailed
+		res.Err = &ItemError{Index: index, ReferenceID: req.ReferenceID, Err: err}
+	}
+
+	if r.opts.Journal != nil && res.Status != StatusFailed {
+		// A journal write failure only weakens resume: CreateDisbursementSafely still prevents a double payout.
+		_ = r.opts.Journal.Record(ctx, res)
+	}
+	return res
+}
+
+// failed returns the FailedError for a disbursement Payara reported FAILED, looking up failure_reason by
+// transaction_id when the response did not include it.
+func (r *Runner) failed(ctx context.Context, d *types.CreateDisbursementResponseData) *FailedError {
+	fe := &FailedError{TransactionID: d.TransactionID}
+	if d.FailureReason != nil {
+		fe.Reason = *d.FailureReason
+	} else if d.TransactionID != "" {
+		if st, err := r.transfers.GetDisbursementStatus(ctx, d.TransactionID); err == nil && st.Data != nil && st.Data.FailureReason != nil {
+			fe.Reason = *st.Data.FailureReason
+		}
+	}
+	return fe
+}
+
+// checkBalance runs EnsureSufficient for the items not yet journaled.
+func (r *Runner) checkBalance(ctx context.Context, reqs []types.CreateDisbursementRequest) error {
+	payouts := make([]payara.Payout, 0, len(reqs))
+	for _, req := range reqs {
+		if r.opts.Journal != nil {
+			if done, err := r.opts.Journal.Submitted(ctx, req.ReferenceID); err == nil && done {
+				continue
+			}
+		}
+		payouts = append(payouts, payara.PayoutOf(req))
+	}
+	if len(payouts) == 0 {
+		return nil
+	}
+	if _, err := r.balance.EnsureSufficient(ctx, payouts...); err != nil {
+		return fmt.Errorf("batch: balance check: %w", err)
+	}
+	return nil
+}
+
+// limiter is a token bucket: one token every 1/rate seconds, holding at most burst tokens.
+type limiter struct {
+	tokens chan struct{}
+	done   chan struct{}
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+	l := &limiter{tokens: make(chan struct{}, burst), done: make(chan struct{})}
+	for i := 0; i < burst; i++ {
+		l.tokens <- struct{}{}
+	}
+	t := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	go func() {
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				select {
+				case l.tokens <- struct{}{}:
+				default:
+				}
+			case <-l.done:
+				return
+			}
+		}
+	}()
+	return l
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case <-l.tokens:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *limiter) stop() {
+	if l != nil {
+		close(l.done)
+	}
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,259 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara"
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// fakeTransfers answers CreateDisbursementSafely from outcome(referenceID) and tracks concurrency.
+type fakeTransfers struct {
+	payara.TransferService // unused methods panic
+	outcome                func(ref string) (types.DisbursementStatus, error)
+	delay                  time.Duration
+
+	calls, inFlight, maxInFlight int32
+	mu                           sync.Mutex
+	refs                         []string
+}
+
+func (f *fakeTransfers) CreateDisbursementSafely(ctx context.Context, req types.CreateDisbursementRequest, _ *payara.SafeDisbursementOptions) (*types.CreateDisbursementResponse, error) {
+	atomic.AddInt32(&f.calls, 1)
+	n := atomic.AddInt32(&f.inFlight, 1)
+	defer atomic.AddInt32(&f.inFlight, -1)
+	for {
+		m := atomic.LoadInt32(&f.maxInFlight)
+		if n <= m || atomic.CompareAndSwapInt32(&f.maxInFlight, m, n) {
+			break
+		}
+	}
+	f.mu.Lock()
+	f.refs = append(f.refs, req.ReferenceID)
+	f.mu.Unlock()
+	time.Sleep(f.delay)
+	status, err := types.DisbursementStatusSuccess, error(nil)
+	if f.outcome != nil {
+		status, err = f.outcome(req.ReferenceID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &types.CreateDisbursementResponse{Success: true, Data: &types.CreateDisbursementResponseData{
+		TransactionID: "T-" + req.ReferenceID, ReferenceID: req.ReferenceID, Amount: req.Amount, Fee: 2500, Status: status,
+	}}, nil
+}
+
+// GetDisbursementStatus reports every looked-up transaction as FAILED with a failure_reason.
+func (f *fakeTransfers) GetDisbursementStatus(_ context.Context, id string) (*types.DisbursementStatusResponse, error) {
+	reason := "account closed"
+	return &types.DisbursementStatusResponse{Success: true, Data: &types.DisbursementStatusData{
+		TransactionID: id, Status: types.DisbursementStatusFailed, FailureReason: &reason,
+	}}, nil
+}
+
+// fakeBalance covers amounts plus a flat 2500 fee per payout from amount.
+type fakeBalance struct {
+	payara.BalanceService
+	amount  types.IDR
+	payouts *[]payara.Payout
+}
+
+func (b fakeBalance) EnsureSufficient(_ context.Context, payouts ...payara.Payout) (*types.BalanceData, error) {
+	if b.payouts != nil {
+		*b.payouts = payouts
+	}
+	var required types.IDR
+	for _, p := range payouts {
+		required += p.Amount + 2500
+	}
+	if required > b.amount {
+		return nil, &payara.InsufficientBalanceError{Required: required, Available: b.amount, Shortfall: required - b.amount}
+	}
+	return &types.BalanceData{Balance: b.amount}, nil
+}
+
+func reqs(refs ...string) []types.CreateDisbursementRequest {
+	out := make([]types.CreateDisbursementRequest, len(refs))
+	for i, ref := range refs {
+		out[i] = types.CreateDisbursementRequest{ReferenceID: ref, Amount: 100000, BankCode: "5", AccountNumber: "12330922231", AccountName: "A"}
+	}
+	return out
+}
+
+func TestRun_BoundedConcurrencyAndSummary(t *testing.T) {
+	ft := &fakeTransfers{delay: 5 * time.Millisecond, outcome: func(ref string) (types.DisbursementStatus, error) {
+		switch ref {
+		case "R2":
+			return types.DisbursementStatusProcess, nil
+		case "R3":
+			return "", &payara.APIError{Code: "INSUFFICIENT_BALANCE", HTTPStatus: 400}
+		case "R4":
+			return types.DisbursementStatusFailed, nil
+		}
+		return types.DisbursementStatusSuccess, nil
+	}}
+	r := NewWithServices(ft, fakeBalance{amount: 10_000_000}, Options{Concurrency: 2})
+	var results []Result
+	sum, err := r.Run(context.Background(), reqs("R1", "R2", "R3", "R4", "R5", "R6"), func(res Result) { results = append(results, res) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ft.maxInFlight > 2 {
+		t.Errorf("max in flight = %d, want <= 2", ft.maxInFlight)
+	}
+	if len(results) != 6 || sum.Total != 6 || sum.Succeeded != 3 || sum.Pending != 1 || sum.Failed != 2 {
+		t.Errorf("summary = %+v", sum)
+	}
+	if sum.TotalFees != 4*2500 || sum.TotalAmount != 4*100000 {
+		t.Errorf("totals: amount=%d fees=%d", sum.TotalAmount, sum.TotalFees)
+	}
+	for _, res := range results {
+		switch res.Request.ReferenceID {
+		case "R3":
+			var ie *ItemError
+			if !errors.As(res.Err, &ie) || ie.Index != 2 || !errors.Is(res.Err, payara.ErrInsufficientBalance) {
+				t.Errorf("R3 error = %v", res.Err)
+			}
+		case "R4":
+			var fe *FailedError
+			if !errors.As(res.Err, &fe) || fe.TransactionID != "T-R4" || fe.Reason != "account closed" {
+				t.Errorf("R4 error = %v", res.Err)
+			}
+		}
+	}
+}
+
+func TestRun_BalanceCheck(t *testing.T) {
+	ft := &fakeTransfers{}
+	var payouts []payara.Payout
+	j := NewMemoryJournal()
+	_ = j.Record(context.Background(), Result{Request: reqs("R0")[0]})
+	r := NewWithServices(ft, fakeBalance{amount: 250000, payouts: &payouts}, Options{Journal: j})
+	_, err := r.Run(context.Background(), reqs("R0", "R1", "R2", "R3"), nil)
+	var ie *payara.InsufficientBalanceError
+	if !errors.As(err, &ie) || ie.Shortfall != 57500 {
+		t.Fatalf("expected InsufficientBalanceError, got %v", err)
+	}
+	if len(payouts) != 3 || payouts[0].BankCode != "5" {
+		t.Errorf("journaled items must be excluded from the check: %+v", payouts)
+	}
+	if !errors.Is(err, payara.ErrInsufficientBalance) {
+		t.Error("balance check error should match ErrInsufficientBalance")
+	}
+	if ft.calls != 0 {
+		t.Errorf("no disbursement may be sent, got %d", ft.calls)
+	}
+}
+
+func TestRun_ResumeSkipsSubmitted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payroll.journal")
+	failR2 := true
+	ft := &fakeTransfers{outcome: func(ref string) (types.DisbursementStatus, error) {
+		if ref == "R2" && failR2 {
+			return "", &payara.APIError{Code: "INVALID_ACCOUNT", HTTPStatus: 400}
+		}
+		if ref == "R3" {
+			return "", &payara.DisbursementUnknownError{ReferenceID: ref}
+		}
+		return types.DisbursementStatusSuccess, nil
+	}}
+
+	j, err := OpenFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewWithServices(ft, nil, Options{SkipBalanceCheck: true, Journal: j})
+	sum, err := r.Run(context.Background(), reqs("R1", "R2", "R3"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Succeeded != 1 || sum.Failed != 1 || sum.Pending != 1 {
+		t.Fatalf("first run summary = %+v", sum)
+	}
+	j.Close()
+
+	// Rerun with a reopened journal: only the failed item is submitted again.
+	failR2 = false
+	ft.refs = nil
+	j, err = OpenFileJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	r = NewWithServices(ft, nil, Options{SkipBalanceCheck: true, Journal: j})
+	sum, err = r.Run(context.Background(), reqs("R1", "R2", "R3"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Skipped != 2 || sum.Succeeded != 1 || len(ft.refs) != 1 || ft.refs[0] != "R2" {
+		t.Errorf("resume summary = %+v, submitted %v", sum, ft.refs)
+	}
+}
+
+func TestRunChan_DuplicateReference(t *testing.T) {
+	ft := &fakeTransfers{}
+	in := make(chan types.CreateDisbursementRequest, 3)
+	for _, req := range reqs("R1", "R1", "R2") {
+		in <- req
+	}
+	close(in)
+	var dup Result
+	sum, err := NewWithServices(ft, nil, Options{}).RunChan(context.Background(), in, func(res Result) {
+		if res.Status == StatusFailed {
+			dup = res
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Succeeded != 2 || sum.Failed != 1 || !errors.Is(dup.Err, ErrDuplicateInBatch) || dup.Index != 1 {
+		t.Errorf("summary = %+v, dup = %+v", sum, dup)
+	}
+}
+
+func TestRun_RateLimit(t *testing.T) {
+	ft := &fakeTransfers{}
+	r := NewWithServices(ft, nil, Options{SkipBalanceCheck: true, Concurrency: 5, RatePerSecond: 50})
+	start := time.Now()
+	if _, err := r.Run(context.Background(), reqs("R1", "R2", "R3", "R4", "R5"), nil); err != nil {
+		t.Fatal(err)
+	}
+	// One immediate start (burst 1), then one every 20ms.
+	if d := time.Since(start); d < 70*time.Millisecond {
+		t.Errorf("5 items at 50/s took %v, want >= ~80ms", d)
+	}
+}
+
+func TestRun_WaitsOutRateLimitError(t *testing.T) {
+	var attempts int32
+	ft := &fakeTransfers{outcome: func(ref string) (types.DisbursementStatus, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			return "", &payara.RateLimitError{RetryAfter: time.Millisecond}
+		}
+		return types.DisbursementStatusSuccess, nil
+	}}
+	sum, err := NewWithServices(ft, nil, Options{SkipBalanceCheck: true}).Run(context.Background(), reqs("R1"), nil)
+	if err != nil || sum.Succeeded != 1 || attempts != 2 {
+		t.Errorf("summary = %+v, attempts = %d, err = %v", sum, attempts, err)
+	}
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ft := &fakeTransfers{delay: 10 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := NewWithServices(ft, nil, Options{SkipBalanceCheck: true, Concurrency: 1})
+	sum, err := r.Run(ctx, reqs("R1", "R2", "R3", "R4", "R5", "R6"), func(Result) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if sum == nil || sum.Total >= 6 {
+		t.Errorf("summary = %+v, want a partial run", sum)
+	}
+}
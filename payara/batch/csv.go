@@ -0,0 +1,287 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// headerAliases maps normalized header names (lowercase, spaces and dashes as underscores) to request fields.
+// Indonesian headers common in finance spreadsheets are included. Generic headers that often hold something
+// else ("Total" with fees, "Bank" with the bank name, "Name") are deliberately not aliases.
+var headerAliases = map[string]string{
+	"reference": "reference_id", "ref": "reference_id", "ref_id": "reference_id",
+	"referenceid": "reference_id", "no_referensi": "reference_id", "referensi": "reference_id",
+	"nominal": "amount", "jumlah": "amount",
+	"bankcode": "bank_code", "kode_bank": "bank_code",
+	"account_no": "account_number", "accountnumber": "account_number", "no_rekening": "account_number",
+	"rekening": "account_number", "nomor_rekening": "account_number", "phone": "account_number",
+	"beneficiary": "account_name", "beneficiary_name": "account_name", "accountname": "account_name",
+	"nama_rekening": "account_name", "nama_penerima": "account_name",
+	"desc": "description", "notes": "description", "note": "description",
+	"remark": "description", "keterangan": "description", "berita": "description",
+}
+
+var canonicalColumns = []string{"reference_id", "amount", "bank_code", "account_number", "account_name", "description"}
+
+var requiredColumns = []string{"reference_id", "amount", "bank_code", "account_number", "account_name"}
+
+// RowError is a problem with one CSV row. Line is the 1-based line in the file; Err is a
+// *types.ValidationError for rule violations, or a parse error.
+type RowError struct {
+	Line        int
+	ReferenceID string
+	Err         error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error { return e.Err }
+
+// CSVError lists every invalid row found by ParseCSV.
+type CSVError struct {
+	Rows []*RowError
+}
+
+func (e *CSVError) Error() string {
+	parts := make([]string, len(e.Rows))
+	for i, r := range e.Rows {
+		parts[i] = r.Error()
+	}
+	return "batch: invalid CSV rows: " + strings.Join(parts, "; ")
+}
+
+// Row is a parsed CSV row: the request and the line it came from.
+type Row struct {
+	Line    int
+	Request types.CreateDisbursementRequest
+}
+
+// ParseCSV reads a payout list. The first row is a header; columns are matched case-insensitively with aliases
+// (e.g. "Nominal" for amount, "No Rekening" for account_number) and unknown columns are ignored. A canonical
+// header ("amount") takes precedence over an alias ("Nominal"); two aliases for the same field are a header error.
+// Comma and semicolon delimiters are detected, and a UTF-8 BOM is skipped. Amounts accept the formats of types.ParseIDR.
+//
+// Every row is validated with CreateDisbursementRequest.Validate and checked for repeated reference_ids. Valid
+// rows are returned even when some are invalid; the invalid ones are reported in a *CSVError. A missing required
+// column or malformed CSV is returned as a plain error with no rows.
+func ParseCSV(r io.Reader) ([]Row, error) {
+	br := bufio.NewReader(r)
+	if bom, _ := br.Peek(3); bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		_, _ = br.Discard(3)
+	}
+	first, _ := br.Peek(4096)
+	if i := bytes.IndexByte(first, '\n'); i >= 0 {
+		first = first[:i]
+	}
+	cr := csv.NewReader(br)
+	if bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+		cr.Comma = ';'
+	}
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("batch: CSV is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("batch: CSV header: %w", err)
+	}
+	cols, err := mapColumns(header)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, c := range requiredColumns {
+		if _, ok := cols[c]; !ok {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("batch: CSV header missing columns: %s", strings.Join(missing, ", "))
+	}
+
+	var rows []Row
+	var rowErrs []*RowError
+	seen := make(map[string]int) // reference_id -> line
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var pe *csv.ParseError
+			if !errors.As(err, &pe) {
+				return nil, fmt.Errorf("batch: reading CSV: %w", err)
+			}
+			// Malformed quoting affects one record; report it and keep going.
+			rowErrs = append(rowErrs, &RowError{Line: pe.StartLine, Err: err})
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		if isBlank(rec) {
+			continue
+		}
+		get := func(field string) string {
+			if i, ok := cols[field]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		req := types.CreateDisbursementRequest{
+			ReferenceID:   get("reference_id"),
+			BankCode:      get("bank_code"),
+			AccountNumber: get("account_number"),
+			AccountName:   get("account_name"),
+			Description:   get("description"),
+		}
+		amount, err := types.ParseIDR(get("amount"))
+		if err != nil {
+			rowErrs = append(rowErrs, &RowError{Line: line, ReferenceID: req.ReferenceID,
+				Err: &types.ValidationError{Errors: []types.FieldError{{Field: "amount", Message: err.Error()}}}})
+			continue
+		}
+		req.Amount = amount
+		if err := req.Validate(); err != nil {
+			rowErrs = append(rowErrs, &RowError{Line: line, ReferenceID: req.ReferenceID, Err: err})
+			continue
+		}
+		if prev, ok := seen[req.ReferenceID]; ok {
+			rowErrs = append(rowErrs, &RowError{Line: line, ReferenceID: req.ReferenceID,
+				Err: fmt.Errorf("%w (first on line %d)", ErrDuplicateInBatch, prev)})
+			continue
+		}
+		seen[req.ReferenceID] = line
+		rows = append(rows, Row{Line: line, Request: req})
+	}
+	if len(rowErrs) > 0 {
+		return rows, &CSVError{Rows: rowErrs}
+	}
+	return rows, nil
+}
+
+// mapColumns maps request fields to column indexes. Canonical headers win over aliases; any other repeat
+// of a field is an error rather than a silent choice of one column.
+func mapColumns(header []string) (map[string]int, error) {
+	canonical := make(map[string]int)
+	aliased := make(map[string][]int)
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(h))
+		key = strings.NewReplacer(" ", "_", "-", "_").Replace(key)
+		if isCanonical(key) {
+			if j, dup := canonical[key]; dup {
+				return nil, fmt.Errorf("batch: CSV header: columns %d and %d are both %s", j+1, i+1, key)
+			}
+			canonical[key] = i
+		} else if field, ok := headerAliases[key]; ok {
+			aliased[field] = append(aliased[field], i)
+		}
+	}
+	cols := canonical
+	for field, idx := range aliased {
+		if _, ok := cols[field]; ok {
+			continue
+		}
+		if len(idx) > 1 {
+			return nil, fmt.Errorf("batch: CSV header: columns %q and %q both map to %s", header[idx[0]], header[idx[1]], field)
+		}
+		cols[field] = idx[0]
+	}
+	return cols, nil
+}
+
+func isCanonical(key string) bool {
+	for _, c := range canonicalColumns {
+		if key == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Requests returns the requests of rows, in order, ready for Runner.Run.
+func Requests(rows []Row) []types.CreateDisbursementRequest {
+	out := make([]types.CreateDisbursementRequest, len(rows))
+	for i, r := range rows {
+		out[i] = r.Request
+	}
+	return out
+}
+
+func isBlank(rec []string) bool {
+	for _, f := range rec {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// resultsHeader is the header written by WriteResultsCSV.
+var resultsHeader = []string{"reference_id", "transaction_id", "status", "amount", "fee", "failure_reason"}
+
+// WriteResultsCSV writes one row per result, ordered by Index, with the header
+// reference_id, transaction_id, status, amount, fee, failure_reason. Amounts are plain integers so
+// spreadsheets read them as numbers. status is the batch ItemStatus; failure_reason is empty on success and
+// Payara's failure_reason for a disbursement it reported FAILED. reference_id is written verbatim so rows
+// match the input; other text cells that start like a formula are prefixed with ' so spreadsheets show them as text.
+func WriteResultsCSV(w io.Writer, results []Result) error {
+	sorted := make([]Result, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Index < sorted[j].Index })
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(resultsHeader); err != nil {
+		return err
+	}
+	for _, r := range sorted {
+		amount, fee, txn := r.Request.Amount, types.IDR(0), ""
+		if r.Response != nil {
+			amount, fee, txn = r.Response.Amount, r.Response.Fee, r.Response.TransactionID
+		}
+		reason := ""
+		if r.Err != nil {
+			reason = failureReason(r.Err)
+		}
+		rec := []string{r.Request.ReferenceID, cellText(txn), string(r.Status),
+			strconv.FormatInt(int64(amount), 10), strconv.FormatInt(int64(fee), 10), cellText(reason)}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// cellText prefixes text that a spreadsheet would evaluate as a formula (=, +, -, @, tab, CR) with a quote.
+func cellText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// failureReason strips the batch item prefix so the spreadsheet shows the API or validation message, or
+// Payara's reason for a FAILED disbursement.
+func failureReason(err error) string {
+	var fe *FailedError
+	if errors.As(err, &fe) && fe.Reason != "" {
+		return fe.Reason
+	}
+	var ie *ItemError
+	if errors.As(err, &ie) && ie.Err != nil {
+		return ie.Err.Error()
+	}
+	return err.Error()
+}
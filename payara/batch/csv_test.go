@@ -0,0 +1,154 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/turahe/payara-go-sdk/payara"
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+func TestParseCSV_AliasesAndRowErrors(t *testing.T) {
+	in := "\xef\xbb\xbfNo Referensi;Nominal;Kode Bank;No Rekening;Nama Penerima;Keterangan;Divisi\n" +
+		"PAY-1;1.500.000;5;12330922231;Budi;Gaji Oktober;Ops\n" +
+		"PAY-2;5000;5;12330922231;Ani;;Ops\n" +
+		"\n" +
+		"PAY-3;Rp 250.000;282;081234567890;Sari;;Ops\n" +
+		"PAY-1;100000;5;12330922231;Budi;;Ops\n" +
+		"PAY-4;abc;5;12330922231;Dedi;;Ops\n"
+	rows, err := ParseCSV(strings.NewReader(in))
+	if len(rows) != 2 || rows[0].Line != 2 || rows[1].Line != 5 {
+		t.Fatalf("rows = %+v", rows)
+	}
+	want := types.CreateDisbursementRequest{ReferenceID: "PAY-1", Amount: 1500000, BankCode: "5", AccountNumber: "12330922231", AccountName: "Budi", Description: "Gaji Oktober"}
+	if rows[0].Request != want {
+		t.Errorf("row 1 = %+v", rows[0].Request)
+	}
+	if rows[1].Request.Amount != 250000 || rows[1].Request.BankCode != "282" {
+		t.Errorf("row 2 = %+v", rows[1].Request)
+	}
+
+	var ce *CSVError
+	if !errors.As(err, &ce) || len(ce.Rows) != 3 {
+		t.Fatalf("expected 3 row errors, got %v", err)
+	}
+	lines := []int{ce.Rows[0].Line, ce.Rows[1].Line, ce.Rows[2].Line}
+	if lines[0] != 3 || lines[1] != 6 || lines[2] != 7 {
+		t.Errorf("error lines = %v, want [3 6 7]", lines)
+	}
+	var ve *types.ValidationError
+	if !errors.As(ce.Rows[0].Err, &ve) || len(ve.Field("amount")) == 0 {
+		t.Errorf("line 3: expected amount validation error, got %v", ce.Rows[0].Err)
+	}
+	if !errors.Is(ce.Rows[1], ErrDuplicateInBatch) {
+		t.Errorf("line 6: expected duplicate, got %v", ce.Rows[1].Err)
+	}
+	if !errors.As(ce.Rows[2].Err, &ve) || len(ve.Field("amount")) == 0 {
+		t.Errorf("line 7: expected amount parse error, got %v", ce.Rows[2].Err)
+	}
+}
+
+func TestParseCSV_MissingColumns(t *testing.T) {
+	_, err := ParseCSV(strings.NewReader("reference_id,amount,bank_code\nR1,100000,5\n"))
+	if err == nil || !strings.Contains(err.Error(), "account_number, account_name") {
+		t.Errorf("got %v", err)
+	}
+	if _, err := ParseCSV(strings.NewReader("")); err == nil {
+		t.Error("expected error for empty input")
+	}
+}
+
+func TestParseCSV_HeaderPrecedence(t *testing.T) {
+	in := "Reference ID,Total,Amount,Bank,Bank Code,Account Number,Account Name\n" +
+		"R1,102500,100000,BCA,5,12330922231,Budi\n"
+	rows, err := ParseCSV(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := rows[0].Request; r.Amount != 100000 || r.BankCode != "5" {
+		t.Errorf("canonical columns must win, got %+v", r)
+	}
+
+	_, err = ParseCSV(strings.NewReader("reference_id,Nominal,Jumlah,bank_code,account_number,account_name\nR1,1,2,5,12330922231,B\n"))
+	if err == nil || !strings.Contains(err.Error(), "both map to amount") {
+		t.Errorf("two aliases for one field: got %v", err)
+	}
+	_, err = ParseCSV(strings.NewReader("reference_id,amount,Amount,bank_code,account_number,account_name\nR1,1,2,5,12330922231,B\n"))
+	if err == nil {
+		t.Error("expected error for a repeated canonical column")
+	}
+}
+
+func TestParseCSV_CommaAndQuotes(t *testing.T) {
+	in := "reference_id,amount,bank_code,account_number,account_name,description\n" +
+		"R1,\"1,000,000\",5,12330922231,\"Budi, S.T.\",\"Bonus \"\"Q3\"\"\"\n"
+	rows, err := ParseCSV(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rows[0].Request
+	if r.Amount != 1000000 || r.AccountName != "Budi, S.T." || r.Description != `Bonus "Q3"` {
+		t.Errorf("got %+v", r)
+	}
+	if reqs := Requests(rows); len(reqs) != 1 || reqs[0] != r {
+		t.Errorf("Requests = %+v", reqs)
+	}
+}
+
+func TestParseCSV_MalformedFirstField(t *testing.T) {
+	in := "reference_id,amount,bank_code,account_number,account_name\n" +
+		"\"REF-1\"x,10000,5,12330922231,Asep\n" +
+		"REF-2,10000,5,12330922231,Budi\n"
+	rows, err := ParseCSV(strings.NewReader(in))
+	var ce *CSVError
+	if !errors.As(err, &ce) || len(ce.Rows) != 1 || ce.Rows[0].Line != 2 {
+		t.Fatalf("expected one row error on line 2, got %v", err)
+	}
+	var pe *csv.ParseError
+	if !errors.As(ce.Rows[0].Err, &pe) {
+		t.Errorf("expected *csv.ParseError, got %v", ce.Rows[0].Err)
+	}
+	if len(rows) != 1 || rows[0].Request.ReferenceID != "REF-2" || rows[0].Line != 3 {
+		t.Errorf("rows = %+v", rows)
+	}
+}
+
+func TestWriteResultsCSV(t *testing.T) {
+	results := []Result{
+		{Index: 1, Request: types.CreateDisbursementRequest{ReferenceID: "R2", Amount: 50000}, Status: StatusFailed,
+			Err: &ItemError{Index: 1, ReferenceID: "R2", Err: &payara.APIError{Code: "INVALID_ACCOUNT", Message: "account not found"}}},
+		{Index: 0, Request: types.CreateDisbursementRequest{ReferenceID: "R1", Amount: 100000}, Status: StatusSucceeded,
+			Response: &types.CreateDisbursementResponseData{TransactionID: "T1", Amount: 100000, Fee: 2500}},
+		{Index: 2, Request: types.CreateDisbursementRequest{ReferenceID: `=HYPERLINK("x")`, Amount: 10000}, Status: StatusFailed,
+			Err: errors.New("-bad")},
+		{Index: 3, Request: types.CreateDisbursementRequest{ReferenceID: "-123", Amount: 20000}, Status: StatusFailed,
+			Response: &types.CreateDisbursementResponseData{TransactionID: "T4", Amount: 20000, Fee: 2500},
+			Err: &ItemError{Index: 3, ReferenceID: "-123", Err: &FailedError{TransactionID: "T4", Reason: "Rekening tidak aktif"}}},
+	}
+	var buf bytes.Buffer
+	if err := WriteResultsCSV(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"reference_id", "transaction_id", "status", "amount", "fee", "failure_reason"},
+		{"R1", "T1", "succeeded", "100000", "2500", ""},
+		{"R2", "", "failed", "50000", "0", "INVALID_ACCOUNT: account not found"},
+		{"=HYPERLINK(\"x\")", "", "failed", "10000", "0", "'-bad"},
+		{"-123", "T4", "failed", "20000", "2500", "Rekening tidak aktif"},
+	}
+	if len(recs) != len(want) {
+		t.Fatalf("records = %v", recs)
+	}
+	for i := range want {
+		if strings.Join(recs[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %v, want %v", i, recs[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// Journal records which items a run has submitted so a rerun after a crash or partial failure skips them.
+// Runner calls Record for succeeded and pending items (Payara may hold the transfer); failed items are not
+// recorded and are retried on the next run. Implementations must be safe for concurrent use.
+type Journal interface {
+	Submitted(ctx context.Context, referenceID string) (bool, error)
+	Record(ctx context.Context, res Result) error
+}
+
+// MemoryJournal is an in-process Journal, useful to resume a run within the same process.
+type MemoryJournal struct {
+	mu   sync.Mutex
+	refs map[string]bool
+}
+
+// NewMemoryJournal returns an empty journal.
+func NewMemoryJournal() *MemoryJournal {
+	return &MemoryJournal{refs: make(map[string]bool)}
+}
+
+// Submitted implements Journal.
+func (j *MemoryJournal) Submitted(_ context.Context, referenceID string) (bool, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.refs[referenceID], nil
+}
+
+// Record implements Journal.
+func (j *MemoryJournal) Record(_ context.Context, res Result) error {
+	j.mu.Lock()
+	j.refs[res.Request.ReferenceID] = true
+	j.mu.Unlock()
+	return nil
+}
+
+// journalEntry is one line of a FileJournal.
+type journalEntry struct {
+	ReferenceID   string     `json:"reference_id"`
+	TransactionID string     `json:"transaction_id,omitempty"`
+	Status        ItemStatus `json:"status"`
+}
+
+// FileJournal is a Journal persisted as JSON lines, one per submitted item, fsynced on every Record
+// so a crashed run can be resumed. Reopen the same path to resume.
+type FileJournal struct {
+	mu   sync.Mutex
+	f    *os.File
+	refs map[string]bool
+}
+
+// OpenFileJournal opens (or creates) the journal at path and loads its entries.
+func OpenFileJournal(path string) (*FileJournal, error) {
+	j := &FileJournal{refs: make(map[string]bool)}
+	if f, err := os.Open(path); err == nil {
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			var e journalEntry
+			if json.Unmarshal(sc.Bytes(), &e) == nil && e.ReferenceID != "" {
+				j.refs[e.ReferenceID] = true
+			} // a torn last line is ignored
+		}
+		err = sc.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	j.f = f
+	return j, nil
+}
+
+// Submitted implements Journal.
+func (j *FileJournal) Submitted(_ context.Context, referenceID string) (bool, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.refs[referenceID], nil
+}
+
+// Record implements Journal.
+func (j *FileJournal) Record(_ context.Context, res Result) error {
+	e := journalEntry{ReferenceID: res.Request.ReferenceID, Status: res.Status}
+	if res.Response != nil {
+		e.TransactionID = res.Response.TransactionID
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if err := j.f.Sync(); err != nil {
+		return err
+	}
+	j.refs[e.ReferenceID] = true
+	return nil
+}
+
+// Close closes the journal file.
+func (j *FileJournal) Close() error {
+	return j.f.Close()
+}
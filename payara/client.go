@@ -5,30 +5,29 @@ package payara
 import (
 	"context"
 	"net/http"
-	"sync"
 	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
 )
 
 // Client is the main API client. It is stateless with respect to request data
-// but holds token state for auth. Safe for concurrent use via mutex for token refresh.
+// but holds token state for auth. Safe for concurrent use; token refresh is single-flight (see authState).
 type Client struct {
 	baseURL     string
 	appID       string
 	appSecret   string
-	accessToken string
-	tokenExpiry time.Time
-	httpClient  *http.Client
+	httpClient  *http.Client // baseHTTP wrapped with retry and middlewares
+	baseHTTP    *http.Client
 	middlewares []Middleware
+	retry       Middleware                 // outermost; from Config.RetryPolicy or WithRetryPolicy
+	opRetry     map[Operation]*RetryPolicy // Config.OperationRetryPolicies
 	logger      Logger
-	auth        *authState
-	mu          sync.Mutex
-}
+	tokens      *tokenManager // shared by clones
+	auth        *authState    // token state for baseURL, from tokens
+	fees        FeeSchedule
 
-// authState holds token and mutex for refresh. Used by Client internally.
-type authState struct {
-	token   string
-	expiry  time.Time
-	refresh func(context.Context) error
+	skipValidation bool
+	destinations   types.DestinationChecker
 }
 
 // Logger is the injectable logger interface. Do not hardcode; inject from caller.
@@ -52,56 +51,70 @@ func NewClient(cfg *Config) *Client {
 		baseURL:     cfg.BaseURL,
 		appID:       cfg.AppID,
 		appSecret:   cfg.AppSecret,
-		httpClient:  cfg.HTTPClient,
+		baseHTTP:    cfg.HTTPClient,
 		middlewares: cfg.Middlewares,
+		retry:       configRetryMiddleware(cfg.RetryPolicy, cfg.OperationRetryPolicies),
+		opRetry:     cfg.OperationRetryPolicies,
 		logger:      cfg.Logger,
+
+		skipValidation: cfg.SkipValidation,
+		destinations:   cfg.Destinations,
 	}
-	client.auth = &authState{refresh: client.login}
-	client.httpClient = wrapWithMiddlewares(client.httpClient, client.middlewares)
+	client.fees = cfg.FeeSchedule
+	if client.fees == nil {
+		client.fees = NewLearnedFeeSchedule(nil)
+	}
+	client.wrap()
+	client.tokens = newTokenManager(cfg.AppID, cfg.AppSecret, cfg.TokenStore, client.logger, cfg.BackgroundTokenRefresh)
+	client.auth = client.tokens.scope(client.baseURL)
 	return client
 }
 
-// WithTimeout returns a new Config with the given HTTP timeout (copy of config if needed).
-// For modifying an existing client, use Config and NewClient.
-func (c *Client) WithTimeout(d time.Duration) *Client {
-	next := c.httpClient
-	if next == nil {
-		next = &http.Client{}
-	}
-	clone := *next
-	clone.Timeout = d
-	return &Client{
-		baseURL:     c.baseURL,
-		appID:       c.appID,
-		appSecret:   c.appSecret,
-		accessToken: c.accessToken,
-		tokenExpiry: c.tokenExpiry,
-		httpClient:  &clone,
-		middlewares: c.middlewares,
-		logger:      c.logger,
-		auth:        c.auth,
-		mu:          c.mu,
+// clone returns a shallow copy of c. Clones share the token manager, so they reuse the same token
+// for the same base URL and never log in twice.
+func (c *Client) clone() *Client {
+	cp := *c
+	return &cp
+}
+
+// wrap rebuilds httpClient from baseHTTP, the retry layer (outermost) and middlewares.
+func (c *Client) wrap() {
+	mws := c.middlewares
+	if c.retry != nil {
+		mws = append([]Middleware{c.retry}, mws...)
 	}
+	c.httpClient = wrapWithMiddlewares(c.baseHTTP, mws)
 }
 
-// WithRetryPolicy returns a new Client with retry middleware prepended.
+// WithTimeout returns a new Client with the given HTTP timeout. The clone shares token state with c.
+func (c *Client) WithTimeout(d time.Duration) *Client {
+	base := *c.baseHTTP
+	base.Timeout = d
+	cp := c.clone()
+	cp.baseHTTP = &base
+	cp.wrap()
+	return cp
+}
+
+// Close stops the background token refresh enabled by Config.BackgroundTokenRefresh, for c and every
+// client derived from it with With* (they share token state). The client remains usable and refreshes
+// its token on use. Close is a no-op without background refresh.
+func (c *Client) Close() error {
+	c.tokens.close()
+	return nil
+}
+
+// WithRetryPolicy returns a new Client whose retry policy is policy, replacing Config.RetryPolicy.
+// A nil policy means DefaultRetryPolicy(); pass NoRetryPolicy() to switch retries off.
+// As with Config.RetryPolicy, CreateDisbursement is not retried unless listed in Config.OperationRetryPolicies.
 func (c *Client) WithRetryPolicy(policy *RetryPolicy) *Client {
-	middlewares := make([]Middleware, 0, len(c.middlewares)+1)
-	middlewares = append(middlewares, RetryMiddleware(policy))
-	middlewares = append(middlewares, c.middlewares...)
-	hc := wrapWithMiddlewares(c.httpClient, middlewares)
-	return &Client{
-		baseURL:     c.baseURL,
-		appID:       c.appID,
-		appSecret:   c.appSecret,
-		accessToken: c.accessToken,
-		tokenExpiry: c.tokenExpiry,
-		httpClient:  hc,
-		middlewares: middlewares,
-		logger:      c.logger,
-		auth:        c.auth,
-		mu:          c.mu,
+	if policy == nil {
+		policy = DefaultRetryPolicy()
 	}
+	cp := c.clone()
+	cp.retry = configRetryMiddleware(policy, c.opRetry)
+	cp.wrap()
+	return cp
 }
 
 // WithMiddleware returns a new Client with the given middleware appended.
@@ -109,36 +122,19 @@ func (c *Client) WithMiddleware(m Middleware) *Client {
 	middlewares := make([]Middleware, len(c.middlewares), len(c.middlewares)+1)
 	copy(middlewares, c.middlewares)
 	middlewares = append(middlewares, m)
-	hc := wrapWithMiddlewares(c.httpClient, middlewares)
-	return &Client{
-		baseURL:     c.baseURL,
-		appID:       c.appID,
-		appSecret:   c.appSecret,
-		accessToken: c.accessToken,
-		tokenExpiry: c.tokenExpiry,
-		httpClient:  hc,
-		middlewares: middlewares,
-		logger:      c.logger,
-		auth:        c.auth,
-		mu:          c.mu,
-	}
+	cp := c.clone()
+	cp.middlewares = middlewares
+	cp.wrap()
+	return cp
 }
 
 // WithEnvironment returns a new Client with base URL set for the given environment.
+// Tokens are scoped per base URL, so the clone logs in to the new environment on its first request.
 func (c *Client) WithEnvironment(env Environment) *Client {
-	baseURL := BaseURLForEnvironment(env)
-	return &Client{
-		baseURL:     baseURL,
-		appID:       c.appID,
-		appSecret:   c.appSecret,
-		accessToken: c.accessToken,
-		tokenExpiry: c.tokenExpiry,
-		httpClient:  c.httpClient,
-		middlewares: c.middlewares,
-		logger:      c.logger,
-		auth:        c.auth,
-		mu:          c.mu,
-	}
+	cp := c.clone()
+	cp.baseURL = BaseURLForEnvironment(env)
+	cp.auth = c.tokens.scope(cp.baseURL)
+	return cp
 }
 
 // BaseURL returns the configured API base URL.
@@ -159,8 +155,14 @@ func (c *Client) Balance() BalanceService {
 	return &balanceService{client: c}
 }
 
+// Accounts returns the AccountService implementation.
+func (c *Client) Accounts() AccountService {
+	return &accountService{client: c}
+}
+
 // Ensure Client implements optional interfaces at compile time.
 var (
 	_ TransferService = (*transferService)(nil)
 	_ BalanceService  = (*balanceService)(nil)
+	_ AccountService  = (*accountService)(nil)
 )
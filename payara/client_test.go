@@ -1,9 +1,13 @@
 package payara
 
 import (
+	"context"
 	"net/http"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/internal/paytest"
 )
 
 func TestNewClient_Defaults(t *testing.T) {
@@ -77,6 +81,68 @@ func TestClient_Transfer_Balance(t *testing.T) {
 	if c.Balance() == nil {
 		t.Error("Balance() nil")
 	}
+	if c.Accounts() == nil {
+		t.Error("Accounts() nil")
+	}
+}
+
+// hostClient returns a client whose login issues a token per host ("tok@<host>") and whose API calls
+// fail with 401 unless they carry that host's token. Logins are counted per host.
+func hostClient(logins map[string]int, mu *sync.Mutex) *Client {
+	return mockClient(&paytest.API{
+		Login: func(req *http.Request, _ int) (*http.Response, error) {
+			mu.Lock()
+			logins[req.URL.Host]++
+			mu.Unlock()
+			return paytest.Login("tok@"+req.URL.Host, 3600)
+		},
+		Handler: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") != "Bearer tok@"+req.URL.Host {
+				return paytest.JSON(401, `{"success":false,"message":"invalid token"}`)
+			}
+			return paytest.JSON(200, `{"success":true,"message":"ok","data":{"balance":"1000","status":"ACTIVE"}}`)
+		},
+	}, &Config{BaseURL: "https://custom.test"})
+}
+
+func TestClient_ClonesShareToken(t *testing.T) {
+	logins, mu := map[string]int{}, &sync.Mutex{}
+	c := hostClient(logins, mu)
+	clones := []*Client{c, c.WithTimeout(time.Second), c.WithMiddleware(func(next http.RoundTripper) http.RoundTripper { return next }), c.WithRetryPolicy(DefaultRetryPolicy())}
+	for i, cl := range clones {
+		if _, err := cl.Balance().GetBalance(context.Background()); err != nil {
+			t.Fatalf("clone %d: %v", i, err)
+		}
+	}
+	if logins["custom.test"] != 1 {
+		t.Errorf("logins = %v, want one for custom.test", logins)
+	}
+}
+
+func TestClient_WithEnvironmentRelogins(t *testing.T) {
+	logins, mu := map[string]int{}, &sync.Mutex{}
+	c := hostClient(logins, mu)
+	if _, err := c.Balance().GetBalance(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	sb := c.WithEnvironment(EnvironmentSandbox)
+	if _, err := sb.Balance().GetBalance(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Back on the original client, the original token is still used.
+	if _, err := c.Balance().GetBalance(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if logins["custom.test"] != 1 || logins["sandbox.payara.id:9090"] != 1 {
+		t.Errorf("logins = %v, want one per environment", logins)
+	}
+	// A second switch to the same environment reuses its token.
+	if _, err := c.WithEnvironment(EnvironmentSandbox).Balance().GetBalance(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if logins["sandbox.payara.id:9090"] != 1 {
+		t.Errorf("sandbox logins = %d, want 1", logins["sandbox.payara.id:9090"])
+	}
 }
 
 func TestAPIError_Error(t *testing.T) {
@@ -105,3 +171,37 @@ func ExampleMockRoundTripper() {
 	// Balance().GetBalance(ctx) would need a prior login; use RoundTripFunc to stub both.
 	_ = client
 }
+
+func TestClient_CloneLoginUsesOwnHTTPClient(t *testing.T) {
+	c := mockClient(&paytest.API{Login: func(req *http.Request, _ int) (*http.Response, error) {
+		select {
+		case <-time.After(300 * time.Millisecond):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		return paytest.Login("tok", 3600)
+	}}, nil)
+
+	start := time.Now()
+	_, err := c.WithTimeout(50 * time.Millisecond).Balance().GetBalance(context.Background())
+	if err == nil || time.Since(start) > 250*time.Millisecond {
+		t.Fatalf("clone login should time out after 50ms: err=%v after %v", err, time.Since(start))
+	}
+
+	var paths []string
+	var mu sync.Mutex
+	logged := c.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return &MockRoundTripper{RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			paths = append(paths, req.URL.Path)
+			mu.Unlock()
+			return next.RoundTrip(req)
+		}}
+	})
+	_, _ = logged.Balance().GetBalance(context.Background())
+	mu.Lock()
+	defer mu.Unlock()
+	if len(paths) == 0 || paths[0] != loginPath {
+		t.Errorf("clone middleware saw %v, want the login first", paths)
+	}
+}
@@ -3,6 +3,8 @@ package payara
 import (
 	"net/http"
 	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
 )
 
 // Config holds client configuration. BaseURL can be set directly or via WithEnvironment.
@@ -13,8 +15,28 @@ type Config struct {
 	HTTPClient  *http.Client
 	Middlewares []Middleware
 	Logger      Logger
-	// RetryPolicy if set is applied when using WithRetryPolicy; optional at construction
+	// RetryPolicy if set is installed by NewClient as the outermost middleware (each attempt passes through
+	// Middlewares). It applies to every operation except CreateDisbursement, which is not idempotent.
 	RetryPolicy *RetryPolicy
+	// OperationRetryPolicies overrides RetryPolicy per operation, e.g. aggressive retries for
+	// OperationBalance. Use NoRetryPolicy() to disable retries for an operation.
+	OperationRetryPolicies map[Operation]*RetryPolicy
+	// SkipValidation disables the client-side CreateDisbursementRequest.Validate check in CreateDisbursement
+	SkipValidation bool
+	// Destinations if set checks bank_code and account_number in CreateDisbursement against the merchant's
+	// supported destinations (e.g. a *banks.Registry; a refreshed registry applies to later calls)
+	Destinations types.DestinationChecker
+	// TokenStore if set shares access tokens with other clients and processes using the same store
+	// (see MemoryTokenStore, FileTokenStore, RedisTokenStore); nil keeps the token in this client only
+	TokenStore TokenStore
+	// BackgroundTokenRefresh if set also refreshes the access token on a timer before it enters the expiry
+	// buffer, so no request waits for a login, even after an idle spell. Call Client.Close to stop it.
+	// Without it the token is refreshed on use: requests in the last minute before the buffer start a
+	// refresh without waiting, but the first request after a longer idle spell waits for the login
+	BackgroundTokenRefresh bool
+	// FeeSchedule estimates fees for BalanceService.EnsureSufficient. Nil uses a LearnedFeeSchedule that
+	// starts at zero and learns from CreateDisbursement responses
+	FeeSchedule FeeSchedule
 }
 
 // withDefaults applies default base URL, HTTP client, and middlewares.
@@ -1,13 +1,24 @@
 package payara
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
 
 // APIError is the structured error for API failures. Doc: success=false, message, error_code
+// errors.Is(err, ErrInsufficientBalance) etc. match on the category derived from Code and HTTPStatus (see Category).
 type APIError struct {
 	Code       string // error_code from response
 	Message    string
 	HTTPStatus int
 	RawBody    []byte
+	Err        error // Underlying cause, if any (e.g. JSON decode error)
 }
 
 func (e *APIError) Error() string {
@@ -17,5 +28,245 @@ func (e *APIError) Error() string {
 	return e.Message
 }
 
+// Is reports whether target is the category sentinel for this error (e.g. ErrInsufficientBalance).
+func (e *APIError) Is(target error) bool {
+	c := e.Category()
+	return c != nil && c == target
+}
+
+// Unwrap returns the underlying cause, if any.
+func (e *APIError) Unwrap() error { return e.Err }
+
+// Category returns the sentinel error for this APIError: the mapping for Code if registered,
+// otherwise the mapping for HTTPStatus, otherwise nil.
+func (e *APIError) Category() error {
+	if c := codeCategory(e.Code); c != nil {
+		return c
+	}
+	switch {
+	case e.HTTPStatus == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.HTTPStatus == http.StatusNotFound:
+		return ErrNotFound
+	case e.HTTPStatus == http.StatusConflict:
+		return ErrDuplicateReference
+	case e.HTTPStatus == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.HTTPStatus == http.StatusBadRequest || e.HTTPStatus == http.StatusUnprocessableEntity:
+		return ErrInvalidRequest
+	case e.HTTPStatus >= 500:
+		return ErrServer
+	}
+	return nil
+}
+
+// codeCategory returns the category registered for a Payara error_code, or nil.
+func codeCategory(code string) error {
+	if code == "" {
+		return nil
+	}
+	errorCodesMu.RLock()
+	defer errorCodesMu.RUnlock()
+	return errorCodes[strings.ToUpper(code)]
+}
+
+// Error categories. Use errors.Is(err, payara.ErrInsufficientBalance) rather than matching APIError.Code.
+var (
+	ErrInsufficientBalance = errors.New("payara: insufficient balance")
+	ErrDuplicateReference  = errors.New("payara: duplicate reference_id")
+	ErrInvalidAccount      = errors.New("payara: invalid destination account")
+	ErrInvalidRequest      = errors.New("payara: invalid request")
+	ErrNotFound            = errors.New("payara: not found")
+	ErrUnauthorized        = errors.New("payara: unauthorized")
+	ErrRateLimited         = errors.New("payara: rate limited")
+	ErrAccountSuspended    = errors.New("payara: merchant account suspended")
+	ErrAccountBlocked      = errors.New("payara: merchant account blocked")
+	ErrServer              = errors.New("payara: server error")
+)
+
+var (
+	errorCodesMu sync.RWMutex
+	// errorCodes maps Payara error_code values (upper case) to categories.
+	errorCodes = map[string]error{
+		"INSUFFICIENT_BALANCE":   ErrInsufficientBalance,
+		"INSUFFICIENT_FUNDS":     ErrInsufficientBalance,
+		"DUPLICATE_REFERENCE":    ErrDuplicateReference,
+		"DUPLICATE_REFERENCE_ID": ErrDuplicateReference,
+		"DUPLICATE_TRANSACTION":  ErrDuplicateReference,
+		"INVALID_ACCOUNT":        ErrInvalidAccount,
+		"INVALID_ACCOUNT_NUMBER": ErrInvalidAccount,
+		"ACCOUNT_NOT_FOUND":      ErrInvalidAccount,
+		"INVALID_BANK_CODE":      ErrInvalidAccount,
+		"VALIDATION_ERROR":       ErrInvalidRequest,
+		"INVALID_REQUEST":        ErrInvalidRequest,
+		"INVALID_AMOUNT":         ErrInvalidRequest,
+		"NOT_FOUND":              ErrNotFound,
+		"TRANSACTION_NOT_FOUND":  ErrNotFound,
+		"DATA_NOT_FOUND":         ErrNotFound,
+		"UNAUTHORIZED":           ErrUnauthorized,
+		"INVALID_TOKEN":          ErrUnauthorized,
+		"TOKEN_EXPIRED":          ErrUnauthorized,
+		"INVALID_CREDENTIALS":    ErrUnauthorized,
+		"RATE_LIMITED":           ErrRateLimited,
+		"RATE_LIMIT_EXCEEDED":    ErrRateLimited,
+		"TOO_MANY_REQUESTS":      ErrRateLimited,
+		"ACCOUNT_SUSPENDED":      ErrAccountSuspended,
+		"MERCHANT_SUSPENDED":     ErrAccountSuspended,
+		"ACCOUNT_BLOCKED":        ErrAccountBlocked,
+		"MERCHANT_BLOCKED":       ErrAccountBlocked,
+		"INTERNAL_ERROR":         ErrServer,
+		"SERVER_ERROR":           ErrServer,
+		"SERVICE_UNAVAILABLE":    ErrServer,
+	}
+)
+
+// RegisterErrorCode maps a Payara error_code to one of the Err* categories, so errors.Is works for codes
+// the SDK does not know yet. Safe for concurrent use; typically called from init.
+func RegisterErrorCode(code string, category error) {
+	errorCodesMu.Lock()
+	errorCodes[strings.ToUpper(code)] = category
+	errorCodesMu.Unlock()
+}
+
+// IsRetryable reports whether repeating the same call may succeed: rate limiting, server errors,
+// timeouts (HTTP 408) and transient network errors. Note that a create-disbursement failure can be
+// retryable and still ambiguous; use CreateDisbursementSafely for those. A *DisbursementUnknownError is
+// never retryable: the payout may already exist, so resubmitting it risks paying twice.
+func IsRetryable(err error) bool {
+	if err == nil || isDisbursementUnknown(err) {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServer) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatus == http.StatusRequestTimeout
+	}
+	return IsTransientNetworkError(err)
+}
+
+// IsPermanent reports whether repeating the same call cannot succeed without changing the request or
+// account state: validation failures, invalid account, duplicate reference, insufficient balance,
+// not found, unauthorized, and suspended/blocked merchant accounts. A *DisbursementUnknownError is never
+// permanent: the payout may have been made, so reconcile it by reference instead of treating it as failed.
+func IsPermanent(err error) bool {
+	if err == nil || isDisbursementUnknown(err) {
+		return false
+	}
+	var ve *types.ValidationError
+	if errors.As(err, &ve) || errors.Is(err, ErrListNotSupported) {
+		return true
+	}
+	for _, c := range []error{ErrInsufficientBalance, ErrDuplicateReference, ErrInvalidAccount, ErrInvalidRequest,
+		ErrNotFound, ErrUnauthorized, ErrAccountSuspended, ErrAccountBlocked} {
+		if errors.Is(err, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNotFound reports whether err proves the requested resource does not exist: a Payara error envelope
+// whose error_code maps to ErrNotFound. A bare HTTP 404 (proxy, unknown route) proves nothing and is not
+// reported; errors.Is(err, ErrNotFound) matches both.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && codeCategory(apiErr.Code) == ErrNotFound
+}
+
+// RateLimitError is returned for HTTP 429 Too Many Requests. RetryAfter is parsed from the Retry-After
+// header or meta.retry_after (zero if neither was sent). errors.As(err, &apiErr) still yields the *APIError.
+type RateLimitError struct {
+	APIError   *APIError
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	msg := "rate limited"
+	if e.APIError != nil {
+		msg = e.APIError.Error()
+	}
+	if e.RetryAfter > 0 {
+		return msg + " (retry after " + e.RetryAfter.String() + ")"
+	}
+	return msg
+}
+
+// Unwrap returns the underlying *APIError.
+func (e *RateLimitError) Unwrap() error {
+	if e.APIError == nil {
+		return nil
+	}
+	return e.APIError
+}
+
+// DisbursementUnknownError is returned by CreateDisbursementSafely when a create attempt failed ambiguously
+// (network error, 5xx) and the follow-up check-status lookup could not prove whether the transfer exists.
+// The disbursement must NOT be resubmitted blindly; reconcile by ReferenceID later (e.g. GetDisbursementStatusByReference).
+type DisbursementUnknownError struct {
+	ReferenceID string
+	CreateErr   error // Error from the ambiguous create attempt
+	LookupErr   error // Error from the check-status lookup
+}
+
+func (e *DisbursementUnknownError) Error() string {
+	return "payara: disbursement " + e.ReferenceID + " outcome unknown: create: " + errString(e.CreateErr) + "; lookup: " + errString(e.LookupErr)
+}
+
+// Unwrap returns both the create and lookup errors for errors.Is / errors.As.
+func (e *DisbursementUnknownError) Unwrap() []error {
+	return []error{e.CreateErr, e.LookupErr}
+}
+
+func isDisbursementUnknown(err error) bool {
+	var ue *DisbursementUnknownError
+	return errors.As(err, &ue)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return err.Error()
+}
+
+// InsufficientBalanceError is returned by BalanceService.EnsureSufficient when the balance does not cover the
+// payouts plus estimated fees. errors.Is(err, ErrInsufficientBalance) holds.
+type InsufficientBalanceError struct {
+	Required  types.IDR // Amounts plus estimated fees
+	Fees      types.IDR // Estimated fees included in Required
+	Available types.IDR
+	Shortfall types.IDR // Required - Available
+}
+
+func (e *InsufficientBalanceError) Error() string {
+	return "payara: insufficient balance: need " + e.Required.String() + " (incl. fees " + e.Fees.String() +
+		"), have " + e.Available.String() + ", short " + e.Shortfall.String()
+}
+
+// Unwrap returns ErrInsufficientBalance.
+func (e *InsufficientBalanceError) Unwrap() error { return ErrInsufficientBalance }
+
+// WaitTimeoutError is returned by WaitForFinalStatus when no final status was observed in time.
+// Last is the most recent status seen (nil if every poll failed); LastErr is the error of the last poll, if it failed.
+type WaitTimeoutError struct {
+	TransactionID string
+	Waited        time.Duration
+	Last          *types.DisbursementStatusData
+	LastErr       error
+}
+
+func (e *WaitTimeoutError) Error() string {
+	status := "none"
+	if e.Last != nil {
+		status = string(e.Last.Status)
+	}
+	return "payara: disbursement " + e.TransactionID + " not final after " + e.Waited.Round(time.Millisecond).String() + " (last status: " + status + ")"
+}
+
+// Unwrap returns context.DeadlineExceeded so errors.Is(err, context.DeadlineExceeded) holds.
+func (e *WaitTimeoutError) Unwrap() error { return context.DeadlineExceeded }
+
 // ErrListNotSupported is returned by ListDisbursement. Payara 1.0 docs do not document a list disbursement endpoint.
 var ErrListNotSupported = errors.New("payara: list disbursement endpoint not documented in API 1.0")
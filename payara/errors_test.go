@@ -0,0 +1,88 @@
+package payara
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+func TestAPIError_IsCategory(t *testing.T) {
+	tests := []struct {
+		err  *APIError
+		want error
+	}{
+		{&APIError{Code: "INSUFFICIENT_BALANCE", HTTPStatus: 400}, ErrInsufficientBalance},
+		{&APIError{Code: "insufficient_balance", HTTPStatus: 400}, ErrInsufficientBalance},
+		{&APIError{Code: "DUPLICATE_REFERENCE", HTTPStatus: 400}, ErrDuplicateReference},
+		{&APIError{Code: "INVALID_ACCOUNT", HTTPStatus: 400}, ErrInvalidAccount},
+		{&APIError{Code: "ACCOUNT_SUSPENDED", HTTPStatus: 403}, ErrAccountSuspended},
+		{&APIError{Code: "ACCOUNT_BLOCKED", HTTPStatus: 403}, ErrAccountBlocked},
+		{&APIError{HTTPStatus: 401}, ErrUnauthorized},
+		{&APIError{HTTPStatus: 429}, ErrRateLimited},
+		{&APIError{HTTPStatus: 409}, ErrDuplicateReference},
+		{&APIError{HTTPStatus: 404}, ErrNotFound},
+		{&APIError{HTTPStatus: 503}, ErrServer},
+		{&APIError{Code: "SOMETHING_NEW", HTTPStatus: 400}, ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		wrapped := fmt.Errorf("create: %w", tt.err)
+		if !errors.Is(wrapped, tt.want) {
+			t.Errorf("%s/%d: errors.Is(%v) = false", tt.err.Code, tt.err.HTTPStatus, tt.want)
+		}
+	}
+	if errors.Is(&APIError{Code: "INSUFFICIENT_BALANCE"}, ErrServer) {
+		t.Error("unexpected category match")
+	}
+	if (&APIError{HTTPStatus: 302}).Category() != nil {
+		t.Error("unmapped status should have nil category")
+	}
+}
+
+func TestAPIError_Unwrap(t *testing.T) {
+	e := &APIError{Message: "decode failed", Err: io.ErrUnexpectedEOF}
+	if !errors.Is(e, io.ErrUnexpectedEOF) {
+		t.Error("Unwrap should expose Err")
+	}
+}
+
+func TestRegisterErrorCode(t *testing.T) {
+	RegisterErrorCode("BENEFICIARY_REJECTED", ErrInvalidAccount)
+	if !errors.Is(&APIError{Code: "BENEFICIARY_REJECTED", HTTPStatus: 400}, ErrInvalidAccount) {
+		t.Error("registered code not mapped")
+	}
+}
+
+func TestIsRetryableAndIsPermanent(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+		permanent bool
+	}{
+		{"nil", nil, false, false},
+		{"server", &APIError{HTTPStatus: 502}, true, false},
+		{"rate limit", &RateLimitError{APIError: &APIError{HTTPStatus: 429}}, true, false},
+		{"timeout 408", &APIError{HTTPStatus: 408}, true, false},
+		{"network", io.ErrUnexpectedEOF, true, false},
+		{"insufficient", &APIError{Code: "INSUFFICIENT_BALANCE", HTTPStatus: 400}, false, true},
+		{"duplicate", &APIError{Code: "DUPLICATE_REFERENCE", HTTPStatus: 400}, false, true},
+		{"suspended", &APIError{Code: "ACCOUNT_SUSPENDED", HTTPStatus: 403}, false, true},
+		{"validation", &types.ValidationError{Errors: []types.FieldError{{Field: "amount", Message: "x"}}}, false, true},
+		{"unknown", errors.New("boom"), false, false},
+		{"disbursement unknown 5xx/5xx", &DisbursementUnknownError{ReferenceID: "R1",
+			CreateErr: &APIError{HTTPStatus: 503}, LookupErr: &APIError{HTTPStatus: 502}}, false, false},
+		{"disbursement unknown 5xx/401", fmt.Errorf("pay: %w", &DisbursementUnknownError{ReferenceID: "R1",
+			CreateErr: io.ErrUnexpectedEOF, LookupErr: &APIError{HTTPStatus: 401}}), false, false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.retryable {
+			t.Errorf("%s: IsRetryable = %v", tt.name, got)
+		}
+		if got := IsPermanent(tt.err); got != tt.permanent {
+			t.Errorf("%s: IsPermanent = %v", tt.name, got)
+		}
+	}
+}
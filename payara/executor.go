@@ -0,0 +1,51 @@
+package payara
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// Do sends an authenticated request to path (relative to the client's base URL, may include a query
+// string) with an optional JSON body, and decodes the Payara envelope with data of type T.
+// It goes through the same pipeline as the built-in services (token refresh, 401 re-login, middlewares)
+// and returns *APIError / *RateLimitError for non-2xx or success=false responses.
+// Use it to call Payara endpoints the SDK does not wrap yet:
+//
+//	out, err := payara.Do[MyData](ctx, client, http.MethodPost, "/api/v1/new-endpoint", reqBody)
+func Do[T any](ctx context.Context, c *Client, method, path string, body interface{}) (*types.Envelope[T], error) {
+	req, err := newJSONRequest(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.doRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return decodeEnvelope[T](resp)
+}
+
+// decodeEnvelope reads and closes resp.Body and decodes it as Envelope[T].
+// Non-2xx status, success=false or an undecodable body yield the error from responseError.
+func decodeEnvelope[T any](resp *http.Response) (*types.Envelope[T], error) {
+	defer resp.Body.Close()
+	raw, err := readAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var out types.Envelope[T]
+	decodeErr := json.Unmarshal(raw, &out)
+	if decodeErr == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 && out.Success {
+		return &out, nil
+	}
+	respErr := responseError(resp, raw)
+	if apiErr, ok := respErr.(*APIError); ok && decodeErr != nil {
+		apiErr.Err = decodeErr
+		if apiErr.Message == "" {
+			apiErr.Message = "response decode failed"
+		}
+	}
+	return nil, respErr
+}
@@ -0,0 +1,69 @@
+package payara
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/turahe/payara-go-sdk/payara/internal/paytest"
+)
+
+func TestDo_CustomEndpoint(t *testing.T) {
+	type quote struct {
+		Fee int64 `json:"fee"`
+	}
+	client := mockClient(&paytest.API{Handler: func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodPost || req.URL.Path != "/api/v1/fee-quote" || req.URL.Query().Get("v") != "2" {
+			t.Errorf("unexpected request %s %s", req.Method, req.URL)
+		}
+		var in map[string]string
+		_ = json.NewDecoder(req.Body).Decode(&in)
+		if in["bank_code"] != "5" {
+			t.Errorf("body: %v", in)
+		}
+		return paytest.JSON(200, `{"success":true,"message":"ok","data":{"fee":2500},"meta":{"version":"1.0"}}`)
+	}}, nil)
+	out, err := Do[quote](context.Background(), client, http.MethodPost, "/api/v1/fee-quote?v=2", map[string]string{"bank_code": "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Data == nil || out.Data.Fee != 2500 || out.Meta == nil || out.Meta.Version != "1.0" {
+		t.Errorf("got %+v", out)
+	}
+}
+
+func TestDo_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		code    string
+		decode  bool
+		isLimit bool
+	}{
+		{"success false on 200", 200, `{"success":false,"message":"Insufficient balance","error_code":"INSUFFICIENT_BALANCE"}`, "INSUFFICIENT_BALANCE", false, false},
+		{"4xx", 400, `{"success":false,"message":"bad","error_code":"INVALID_ACCOUNT"}`, "INVALID_ACCOUNT", false, false},
+		{"undecodable 200", 200, `<html>`, "", true, false},
+		{"429", 429, `{"success":false,"message":"slow","error_code":"RATE_LIMITED"}`, "RATE_LIMITED", false, true},
+	}
+	for _, tt := range tests {
+		client := mockClient(&paytest.API{Handler: func(*http.Request) (*http.Response, error) { return paytest.JSON(tt.status, tt.body) }}, nil)
+		_, err := Do[json.RawMessage](context.Background(), client, http.MethodGet, "/api/v1/anything", nil)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("%s: expected *APIError, got %v", tt.name, err)
+		}
+		if apiErr.Code != tt.code || apiErr.HTTPStatus != tt.status {
+			t.Errorf("%s: got %+v", tt.name, apiErr)
+		}
+		if tt.decode && (apiErr.Err == nil || apiErr.Message == "") {
+			t.Errorf("%s: decode error not recorded: %+v", tt.name, apiErr)
+		}
+		var rl *RateLimitError
+		if errors.As(err, &rl) != tt.isLimit {
+			t.Errorf("%s: RateLimitError = %v", tt.name, !tt.isLimit)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package payara
+
+import (
+	"sync"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// FeeSchedule estimates the fee Payara charges for a disbursement, so balance checks can include fees
+// before any money moves. Implementations must be safe for concurrent use.
+type FeeSchedule interface {
+	Fee(bankCode string, amount types.IDR) types.IDR
+}
+
+// FeeObserver is implemented by fee schedules that learn from completed disbursements. The client calls
+// ObserveFee with the fee of every successful CreateDisbursement response.
+type FeeObserver interface {
+	ObserveFee(bankCode string, amount, fee types.IDR)
+}
+
+// StaticFeeSchedule charges a fixed fee per bank / e-wallet code, falling back to Default.
+type StaticFeeSchedule struct {
+	Default types.IDR
+	ByCode  map[string]types.IDR
+}
+
+// Fee implements FeeSchedule.
+func (s StaticFeeSchedule) Fee(bankCode string, _ types.IDR) types.IDR {
+	if f, ok := s.ByCode[bankCode]; ok {
+		return f
+	}
+	return s.Default
+}
+
+// LearnedFeeSchedule remembers the highest fee seen per bank code from create responses and uses
+// Fallback (if set) for codes it has not seen yet. It is the client's default schedule.
+type LearnedFeeSchedule struct {
+	Fallback FeeSchedule
+
+	mu     sync.RWMutex
+	byCode map[string]types.IDR
+}
+
+// NewLearnedFeeSchedule returns a schedule that starts from fallback (may be nil, meaning zero fees).
+func NewLearnedFeeSchedule(fallback FeeSchedule) *LearnedFeeSchedule {
+	return &LearnedFeeSchedule{Fallback: fallback, byCode: make(map[string]types.IDR)}
+}
+
+// Fee implements FeeSchedule.
+func (s *LearnedFeeSchedule) Fee(bankCode string, amount types.IDR) types.IDR {
+	s.mu.RLock()
+	f, ok := s.byCode[bankCode]
+	s.mu.RUnlock()
+	if ok {
+		return f
+	}
+	if s.Fallback != nil {
+		return s.Fallback.Fee(bankCode, amount)
+	}
+	return 0
+}
+
+// ObserveFee implements FeeObserver. The highest fee per code is kept so estimates err on the safe side.
+func (s *LearnedFeeSchedule) ObserveFee(bankCode string, _ types.IDR, fee types.IDR) {
+	s.mu.Lock()
+	if fee > s.byCode[bankCode] {
+		s.byCode[bankCode] = fee
+	}
+	s.mu.Unlock()
+}
+
+var (
+	_ FeeSchedule = StaticFeeSchedule{}
+	_ FeeSchedule = (*LearnedFeeSchedule)(nil)
+	_ FeeObserver = (*LearnedFeeSchedule)(nil)
+)
@@ -6,6 +6,9 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/turahe/payara-go-sdk/payara/types"
 )
@@ -48,6 +51,41 @@ func newJSONRequest(ctx context.Context, method, url string, body interface{}) (
 	return req, nil
 }
 
+// replayableRequest returns req with GetBody set so its body can be resent by retries and the 401 re-login.
+// Requests built by newJSONRequest already have GetBody; other bodies are buffered into memory once.
+func replayableRequest(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return req, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(b))
+	out.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+	out.ContentLength = int64(len(b))
+	return out, nil
+}
+
+// rewindRequest returns a copy of req with a fresh body for resending. The previous attempt has already
+// consumed req.Body, so the body is rebuilt from GetBody (see replayableRequest).
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	out := req.Clone(req.Context())
+	if req.GetBody == nil {
+		return out, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	out.Body = body
+	return out, nil
+}
+
 func readAll(r io.Reader) ([]byte, error) {
 	if r == nil {
 		return nil, nil
@@ -79,3 +117,36 @@ func parseErrorResponse(raw []byte, statusCode int) *APIError {
 		RawBody:    raw,
 	}
 }
+
+// responseError builds the error for a failed API response. HTTP 429 becomes *RateLimitError
+// (wrapping the *APIError) with the server's retry hint; everything else is *APIError.
+func responseError(resp *http.Response, raw []byte) error {
+	apiErr := parseErrorResponse(raw, resp.StatusCode)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		d, _ := parseRetryAfter(resp.Header, raw)
+		return &RateLimitError{APIError: apiErr, RetryAfter: d}
+	}
+	return apiErr
+}
+
+// parseRetryAfter reads the delay from the Retry-After header (seconds or HTTP-date) or,
+// failing that, from meta.retry_after (seconds) in the JSON body.
+func parseRetryAfter(h http.Header, raw []byte) (time.Duration, bool) {
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		if sec, err := strconv.Atoi(v); err == nil && sec >= 0 {
+			return time.Duration(sec) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			d := time.Until(t)
+			if d < 0 {
+				d = 0
+			}
+			return d, true
+		}
+	}
+	var er types.ErrorResponse
+	if json.Unmarshal(raw, &er) == nil && er.Meta != nil && er.Meta.RetryAfter != nil && *er.Meta.RetryAfter >= 0 {
+		return time.Duration(*er.Meta.RetryAfter) * time.Second, true
+	}
+	return 0, false
+}
@@ -9,13 +9,22 @@ import (
 // TransferService provides disbursement and status operations. Doc: Disbursement, Check Status
 type TransferService interface {
 	CreateDisbursement(ctx context.Context, req types.CreateDisbursementRequest) (*types.CreateDisbursementResponse, error)
+	CreateDisbursementSafely(ctx context.Context, req types.CreateDisbursementRequest, opts *SafeDisbursementOptions) (*types.CreateDisbursementResponse, error)
 	GetDisbursementStatus(ctx context.Context, id string) (*types.DisbursementStatusResponse, error)
+	GetDisbursementStatusByReference(ctx context.Context, referenceID string) (*types.DisbursementStatusResponse, error)
+	WaitForFinalStatus(ctx context.Context, id string, opts *WaitOptions) (*types.DisbursementStatusData, error)
 	ListDisbursement(ctx context.Context, filter types.ListFilter) (*types.DisbursementListResponse, error)
 }
 
 // BalanceService provides balance inquiry. Doc: Get Balance
 type BalanceService interface {
 	GetBalance(ctx context.Context) (*types.BalanceResponse, error)
+	EnsureSufficient(ctx context.Context, payouts ...Payout) (*types.BalanceData, error)
+}
+
+// AccountService provides beneficiary account inquiry. Doc: Check Account
+type AccountService interface {
+	CheckAccount(ctx context.Context, req types.CheckAccountRequest) (*types.CheckAccountResponse, error)
 }
 
 // transferService implements TransferService
@@ -27,3 +36,8 @@ type transferService struct {
 type balanceService struct {
 	client *Client
 }
+
+// accountService implements AccountService
+type accountService struct {
+	client *Client
+}
@@ -0,0 +1,56 @@
+// Package paytest provides a fake Payara API for the SDK's own tests.
+package paytest
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// LoginPath is the path API answers with Login.
+const LoginPath = "/api/v1/login"
+
+// API is a login-aware http.RoundTripper. It answers POST /api/v1/login with Login and sends every
+// other request to Handler, so a test only describes the endpoints it exercises.
+type API struct {
+	// Login answers login requests; n is the 1-based number of this login. Nil returns Login("tok", 3600).
+	Login func(req *http.Request, n int) (*http.Response, error)
+	// Handler answers all other requests. Nil returns 404.
+	Handler func(req *http.Request) (*http.Response, error)
+
+	logins atomic.Int32
+}
+
+// JSON returns a response with the given status and JSON body, in the form RoundTrip returns.
+func JSON(status int, body string) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+	}, nil
+}
+
+// Login returns a successful login response for token, valid for expiresIn seconds.
+func Login(token string, expiresIn int) (*http.Response, error) {
+	return JSON(200, `{"success":true,"message":"ok","data":{"access_token":"`+token+
+		`","token_type":"Bearer","expires_in":`+strconv.Itoa(expiresIn)+`,"merchant_id":"M1","merchant_name":"Test"}}`)
+}
+
+func (a *API) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == LoginPath {
+		n := int(a.logins.Add(1))
+		if a.Login != nil {
+			return a.Login(req, n)
+		}
+		return Login("tok", 3600)
+	}
+	if a.Handler != nil {
+		return a.Handler(req)
+	}
+	return JSON(404, `{"success":false,"message":"not found"}`)
+}
+
+// Logins returns how many login requests a has answered.
+func (a *API) Logins() int { return int(a.logins.Load()) }
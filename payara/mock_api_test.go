@@ -0,0 +1,24 @@
+package payara
+
+import (
+	"net/http"
+
+	"github.com/turahe/payara-go-sdk/payara/internal/paytest"
+)
+
+// mockClient returns a client that sends its requests through api. Unset AppID, AppSecret and BaseURL
+// in cfg (which may be nil) get test values; cfg.HTTPClient is replaced.
+func mockClient(api *paytest.API, cfg *Config) *Client {
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.AppID == "" {
+		c.AppID, c.AppSecret = "a", "b"
+	}
+	if c.BaseURL == "" {
+		c.BaseURL = "https://test.payara.id"
+	}
+	c.HTTPClient = &http.Client{Transport: api}
+	return NewClient(&c)
+}
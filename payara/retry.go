@@ -1,35 +1,64 @@
 package payara
 
 import (
+	"bytes"
+	"context"
+	"math/rand"
 	"net/http"
 	"time"
 )
 
-// RetryPolicy configures exponential backoff. Retry only on 5xx and network errors.
+// Jitter selects how RetryMiddleware randomizes backoff so that clients retrying together spread out.
+// See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+type Jitter int
+
+const (
+	// JitterNone waits exactly the exponential backoff.
+	JitterNone Jitter = iota
+	// JitterFull waits a random duration in [0, backoff].
+	JitterFull
+	// JitterEqual waits backoff/2 plus a random duration in [0, backoff/2].
+	JitterEqual
+	// JitterDecorrelated waits a random duration in [Initial, previous wait * 3], capped at MaxBackoff.
+	JitterDecorrelated
+)
+
+// RetryPolicy configures exponential backoff. Classifier decides what is retried; the default retries
+// 408, 429, 500, 502, 503, 504 and transient network errors (see DefaultRetryClassifier).
+// On 429 the wait comes from Retry-After or meta.retry_after when present, otherwise from the backoff.
 type RetryPolicy struct {
-	MaxRetries int           // Max retry attempts (default 3)
-	Initial    time.Duration // Initial backoff (default 1s)
-	MaxBackoff time.Duration // Max backoff cap (default 30s)
-	Multiplier float64       // Backoff multiplier (default 2)
+	MaxRetries    int             // Max retry attempts (default 3; negative disables retries)
+	Initial       time.Duration   // Initial backoff (default 1s)
+	MaxBackoff    time.Duration   // Max backoff cap (default 30s)
+	Multiplier    float64         // Backoff multiplier (default 2)
+	Jitter        Jitter          // Backoff randomization (default JitterNone)
+	MaxRetryAfter time.Duration   // Longest server-requested 429 wait to honor; longer waits are returned to the caller (default 60s)
+	Classifier    RetryClassifier // Decides which failures are retried (default DefaultRetryClassifier())
 }
 
 // DefaultRetryPolicy returns a policy suitable for most use cases.
 func DefaultRetryPolicy() *RetryPolicy {
 	return &RetryPolicy{
-		MaxRetries: 3,
-		Initial:    time.Second,
-		MaxBackoff: 30 * time.Second,
-		Multiplier: 2,
+		MaxRetries:    3,
+		Initial:       time.Second,
+		MaxBackoff:    30 * time.Second,
+		Multiplier:    2,
+		Jitter:        JitterFull,
+		MaxRetryAfter: time.Minute,
 	}
 }
 
-// RetryMiddleware returns a Middleware that retries on 5xx and connection errors with exponential backoff.
+// RetryMiddleware returns a Middleware that retries failures accepted by the policy's Classifier with exponential backoff.
+// Backoff waits are aborted as soon as the request context is cancelled.
 func RetryMiddleware(policy *RetryPolicy) Middleware {
 	if policy == nil {
 		policy = DefaultRetryPolicy()
 	}
 	maxRetries := policy.MaxRetries
-	if maxRetries <= 0 {
+	if maxRetries < 0 {
+		return func(next http.RoundTripper) http.RoundTripper { return next }
+	}
+	if maxRetries == 0 {
 		maxRetries = 3
 	}
 	initial := policy.Initial
@@ -44,65 +73,123 @@ func RetryMiddleware(policy *RetryPolicy) Middleware {
 	if mult <= 0 {
 		mult = 2
 	}
+	maxRetryAfter := policy.MaxRetryAfter
+	if maxRetryAfter <= 0 {
+		maxRetryAfter = time.Minute
+	}
+	classify := policy.Classifier
+	if classify == nil {
+		classify = DefaultRetryClassifier()
+	}
 	return func(next http.RoundTripper) http.RoundTripper {
 		return &retryRoundTripper{
-			next:       next,
-			maxRetries: maxRetries,
-			initial:    initial,
-			maxBackoff: maxBackoff,
-			mult:       mult,
+			next:          next,
+			maxRetries:    maxRetries,
+			initial:       initial,
+			maxBackoff:    maxBackoff,
+			mult:          mult,
+			jitter:        policy.Jitter,
+			maxRetryAfter: maxRetryAfter,
+			classify:      classify,
+			randInt63n:    rand.Int63n,
 		}
 	}
 }
 
 type retryRoundTripper struct {
-	next       http.RoundTripper
-	maxRetries int
-	initial    time.Duration
-	maxBackoff time.Duration
-	mult       float64
+	next          http.RoundTripper
+	maxRetries    int
+	initial       time.Duration
+	maxBackoff    time.Duration
+	mult          float64
+	jitter        Jitter
+	maxRetryAfter time.Duration
+	classify      RetryClassifier
+	randInt63n    func(n int64) int64
 }
 
 func (r *retryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
-	var lastErr error
-	var lastResp *http.Response
+	req, err := replayableRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	ctx := req.Context()
 	backoff := r.initial
-	for attempt := 0; attempt <= r.maxRetries; attempt++ {
-		resp, err := r.next.RoundTrip(req)
+	prevWait := r.initial
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			if attemptReq, err = rewindRequest(req); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := r.next.RoundTrip(attemptReq)
+		last := attempt >= r.maxRetries
 		if err != nil {
-			lastErr = err
-			lastResp = nil
-			if !shouldRetryError(err) {
+			if last || ctx.Err() != nil || !r.classify(nil, nil, err) {
 				return nil, err
 			}
-			if attempt < r.maxRetries {
-				sleep(backoff)
-				backoff = nextBackoff(backoff, r.maxBackoff, r.mult)
+			prevWait = r.wait(backoff, prevWait)
+			if err := sleep(ctx, prevWait); err != nil {
+				return nil, err
 			}
+			backoff = nextBackoff(backoff, r.maxBackoff, r.mult)
 			continue
 		}
-		if resp.StatusCode < 500 {
+		if resp.StatusCode < 400 || last {
 			return resp, nil
 		}
-		lastResp = resp
-		lastErr = nil
-		if attempt < r.maxRetries {
-			resp.Body.Close()
-			sleep(backoff)
-			backoff = nextBackoff(backoff, r.maxBackoff, r.mult)
-		} else {
+		raw, _ := readAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = noopReadCloser{bytes.NewReader(raw)}
+		if !r.classify(resp, raw, nil) {
 			return resp, nil
 		}
+		d, ok := time.Duration(0), false
+		if resp.StatusCode == http.StatusTooManyRequests {
+			d, ok = parseRetryAfter(resp.Header, raw)
+			if ok && d > r.maxRetryAfter {
+				// Server asks for longer than we are willing to block; let the caller reschedule.
+				return resp, nil
+			}
+		}
+		if !ok {
+			d = r.wait(backoff, prevWait)
+		}
+		prevWait = d
+		if err := sleep(ctx, d); err != nil {
+			return nil, err
+		}
+		backoff = nextBackoff(backoff, r.maxBackoff, r.mult)
 	}
-	if lastResp != nil {
-		return lastResp, nil
+}
+
+// wait applies the configured jitter to the current exponential backoff.
+// prev is the previous wait (used by JitterDecorrelated).
+func (r *retryRoundTripper) wait(backoff, prev time.Duration) time.Duration {
+	switch r.jitter {
+	case JitterFull:
+		return r.randBetween(0, backoff)
+	case JitterEqual:
+		half := backoff / 2
+		return half + r.randBetween(0, backoff-half)
+	case JitterDecorrelated:
+		d := r.randBetween(r.initial, prev*3)
+		if d > r.maxBackoff {
+			return r.maxBackoff
+		}
+		return d
+	default:
+		return backoff
 	}
-	return nil, lastErr
 }
 
-func shouldRetryError(err error) bool {
-	// Retry on temporary network errors; could check for net.Error and Temporary()
-	return err != nil
+// randBetween returns a random duration in [lo, hi].
+func (r *retryRoundTripper) randBetween(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	return lo + time.Duration(r.randInt63n(int64(hi-lo)+1))
 }
 
 func nextBackoff(current, max time.Duration, mult float64) time.Duration {
@@ -113,10 +200,14 @@ func nextBackoff(current, max time.Duration, mult float64) time.Duration {
 	return next
 }
 
-func sleep(d time.Duration) {
+// sleep waits for d or until ctx is done, whichever comes first. Returns ctx.Err() on cancellation.
+func sleep(ctx context.Context, d time.Duration) error {
 	t := time.NewTimer(d)
 	defer t.Stop()
 	select {
 	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
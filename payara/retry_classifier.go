@@ -0,0 +1,105 @@
+package payara
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// RetryClassifier reports whether a failed attempt should be retried.
+// For transport failures resp and body are nil and err is set. For HTTP responses with status >= 400,
+// err is nil and body holds the buffered response body (so error_code can be inspected).
+type RetryClassifier func(resp *http.Response, body []byte, err error) bool
+
+// DefaultRetryableStatuses are the HTTP statuses retried by DefaultRetryClassifier.
+var DefaultRetryableStatuses = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryClassifier retries DefaultRetryableStatuses and transient network errors (see IsTransientNetworkError).
+func DefaultRetryClassifier() RetryClassifier {
+	return NewRetryClassifier(DefaultRetryableStatuses, nil)
+}
+
+// NewRetryClassifier returns a RetryClassifier that retries the given HTTP statuses, any response whose
+// Payara error_code is in errorCodes (regardless of status), and transient network errors.
+func NewRetryClassifier(statuses []int, errorCodes []string) RetryClassifier {
+	statusSet := make(map[int]struct{}, len(statuses))
+	for _, s := range statuses {
+		statusSet[s] = struct{}{}
+	}
+	codeSet := make(map[string]struct{}, len(errorCodes))
+	for _, c := range errorCodes {
+		codeSet[c] = struct{}{}
+	}
+	return func(resp *http.Response, body []byte, err error) bool {
+		if err != nil {
+			return IsTransientNetworkError(err)
+		}
+		if resp == nil {
+			return false
+		}
+		if _, ok := statusSet[resp.StatusCode]; ok {
+			return true
+		}
+		if len(codeSet) == 0 || len(body) == 0 {
+			return false
+		}
+		var er types.ErrorResponse
+		if json.Unmarshal(body, &er) != nil {
+			return false
+		}
+		_, ok := codeSet[er.ErrorCode]
+		return ok
+	}
+}
+
+// IsTransientNetworkError reports whether err is a transport failure worth retrying: timeouts, connection
+// resets/refusals and truncated responses. Context cancellation, TLS certificate failures and DNS
+// NXDOMAIN are permanent and return false, as does any error not recognised as transient.
+func IsTransientNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var certErr *tls.CertificateVerificationError
+	var unknownAuth x509.UnknownAuthorityError
+	var hostErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	if errors.As(err, &certErr) || errors.As(err, &unknownAuth) || errors.As(err, &hostErr) || errors.As(err, &invalidCert) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsNotFound {
+			return false
+		}
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package payara
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
+
+func TestIsTransientNetworkError(t *testing.T) {
+	wrap := func(err error) error {
+		return &url.Error{Op: "Post", URL: "https://test.payara.id", Err: err}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"timeout", wrap(&net.OpError{Op: "read", Err: timeoutErr{}}), true},
+		{"conn reset", wrap(&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}), true},
+		{"conn refused", wrap(&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}), true},
+		{"unexpected EOF", wrap(io.ErrUnexpectedEOF), true},
+		{"dns timeout", wrap(&net.DNSError{Err: "timeout", Name: "x", IsTimeout: true}), true},
+		{"dns nxdomain", wrap(&net.DNSError{Err: "no such host", Name: "x", IsNotFound: true}), false},
+		{"canceled", wrap(context.Canceled), false},
+		{"deadline", wrap(context.DeadlineExceeded), false},
+		{"unknown authority", wrap(x509.UnknownAuthorityError{}), false},
+		{"hostname", wrap(x509.HostnameError{Host: "x"}), false},
+		{"unknown", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		if got := IsTransientNetworkError(tt.err); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewRetryClassifier_StatusesAndErrorCodes(t *testing.T) {
+	classify := NewRetryClassifier([]int{503}, []string{"BANK_TIMEOUT"})
+	tests := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{503, ``, true},
+		{500, `{"success":false,"error_code":"INTERNAL"}`, false},
+		{400, `{"success":false,"error_code":"BANK_TIMEOUT"}`, true},
+		{400, `{"success":false,"error_code":"INVALID_ACCOUNT"}`, false},
+		{400, `not json`, false},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status}
+		if got := classify(resp, []byte(tt.body), nil); got != tt.want {
+			t.Errorf("status=%d body=%s: got %v, want %v", tt.status, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestRetryMiddleware_PermanentErrorNotRetried(t *testing.T) {
+	var calls int
+	mock := &MockRoundTripper{RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+		calls++
+		return nil, fmt.Errorf("dial: %w", &net.DNSError{Err: "no such host", Name: "test.payara.id", IsNotFound: true})
+	}}
+	rt := RetryMiddleware(&RetryPolicy{MaxRetries: 3, Initial: time.Millisecond})(mock)
+	req, _ := http.NewRequest(http.MethodGet, "https://test.payara.id/api/v1/balance", nil)
+	if _, err := rt.RoundTrip(req); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 1 {
+		t.Errorf("calls: got %d, want 1", calls)
+	}
+}
+
+func TestRetryMiddleware_CustomClassifier(t *testing.T) {
+	var calls int
+	mock := &MockRoundTripper{RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+		calls++
+		status := 409
+		if calls == 2 {
+			status = 200
+		}
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"error_code":"LOCKED"}`))}, nil
+	}}
+	policy := &RetryPolicy{MaxRetries: 2, Initial: time.Millisecond, Classifier: NewRetryClassifier(nil, []string{"LOCKED"})}
+	rt := RetryMiddleware(policy)(mock)
+	req, _ := http.NewRequest(http.MethodGet, "https://test.payara.id/api/v1/balance", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 || calls != 2 {
+		t.Errorf("status=%d calls=%d", resp.StatusCode, calls)
+	}
+}
+
+func TestRetryMiddleware_NonRetryableStatusKeepsBody(t *testing.T) {
+	mock := &MockRoundTripper{StatusCode: 400, Body: []byte(`{"success":false,"message":"bad","error_code":"INVALID_ACCOUNT"}`)}
+	rt := RetryMiddleware(&RetryPolicy{MaxRetries: 2, Initial: time.Millisecond})(mock)
+	req, _ := http.NewRequest(http.MethodGet, "https://test.payara.id/api/v1/balance", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(b), "INVALID_ACCOUNT") {
+		t.Errorf("body: got %q", b)
+	}
+}
@@ -0,0 +1,90 @@
+package payara
+
+import (
+	"context"
+	"net/http"
+)
+
+// Operation names an SDK call so middlewares can treat calls differently, e.g. retry reads but not creates.
+// Built-in services tag their requests; use ContextWithOperation to tag calls made through Do or DoRequest.
+type Operation string
+
+const (
+	OperationLogin              Operation = "login"
+	OperationCreateDisbursement Operation = "create_disbursement"
+	OperationDisbursementStatus Operation = "disbursement_status"
+	OperationBalance            Operation = "balance"
+	OperationCheckAccount       Operation = "check_account"
+)
+
+type operationKey struct{}
+
+// ContextWithOperation returns ctx tagged with op.
+func ContextWithOperation(ctx context.Context, op Operation) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+// OperationFromContext returns the operation ctx was tagged with, or "" for untagged requests.
+func OperationFromContext(ctx context.Context) Operation {
+	op, _ := ctx.Value(operationKey{}).(Operation)
+	return op
+}
+
+// NoRetryPolicy returns a policy that never retries. Use it in Config.OperationRetryPolicies to switch
+// retries off for one operation, or with WithRetryPolicy to switch them off for a client.
+func NoRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{MaxRetries: -1}
+}
+
+// RetryMiddlewareByOperation returns a Middleware that retries each request with the policy for its
+// operation (see OperationFromContext), falling back to def. A nil def leaves operations without their
+// own policy unretried; a nil policy or one with MaxRetries < 0 disables retries for that operation.
+func RetryMiddlewareByOperation(def *RetryPolicy, perOp map[Operation]*RetryPolicy) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		rt := &operationRoundTripper{next: next, byOp: make(map[Operation]http.RoundTripper, len(perOp))}
+		if def != nil {
+			rt.def = RetryMiddleware(def)(next)
+		}
+		for op, p := range perOp {
+			if p == nil {
+				p = NoRetryPolicy()
+			}
+			rt.byOp[op] = RetryMiddleware(p)(next)
+		}
+		return rt
+	}
+}
+
+type operationRoundTripper struct {
+	next http.RoundTripper
+	def  http.RoundTripper
+	byOp map[Operation]http.RoundTripper
+}
+
+func (o *operationRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt, ok := o.byOp[OperationFromContext(req.Context())]; ok {
+		return rt.RoundTrip(req)
+	}
+	if o.def != nil {
+		return o.def.RoundTrip(req)
+	}
+	return o.next.RoundTrip(req)
+}
+
+// configRetryMiddleware builds the client's retry layer from a default policy (Config.RetryPolicy or
+// WithRetryPolicy) and Config.OperationRetryPolicies, or nil when retries are not configured.
+// CreateDisbursement is not idempotent, so def does not apply to it; retry it only by listing it in
+// opPolicies (prefer CreateDisbursementSafely).
+func configRetryMiddleware(def *RetryPolicy, opPolicies map[Operation]*RetryPolicy) Middleware {
+	if def == nil && len(opPolicies) == 0 {
+		return nil
+	}
+	perOp := make(map[Operation]*RetryPolicy, len(opPolicies)+1)
+	perOp[OperationCreateDisbursement] = NoRetryPolicy()
+	for op, p := range opPolicies {
+		perOp[op] = p
+	}
+	return RetryMiddlewareByOperation(def, perOp)
+}
+
+var _ http.RoundTripper = (*operationRoundTripper)(nil)
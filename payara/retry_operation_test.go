@@ -0,0 +1,136 @@
+package payara
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/internal/paytest"
+)
+
+var fastRetry = &RetryPolicy{MaxRetries: 2, Initial: time.Millisecond, MaxBackoff: time.Millisecond}
+
+// flakyClient returns a client built from cfg whose API calls fail once with 503, then succeed.
+// calls counts API (non-login) requests by path; seen counts requests passing through cfg.Middlewares.
+func flakyClient(cfg *Config, calls map[string]*int32, seen *int32) *Client {
+	cfg.Middlewares = []Middleware{func(next http.RoundTripper) http.RoundTripper {
+		return &MockRoundTripper{RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(seen, 1)
+			return next.RoundTrip(req)
+		}}
+	}}
+	return mockClient(&paytest.API{Handler: func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(calls[req.URL.Path], 1) == 1 {
+			return paytest.JSON(503, `{"success":false,"message":"unavailable"}`)
+		}
+		return paytest.JSON(200, `{"success":true,"message":"ok","data":{"balance":"1000","transaction_id":"T1","status":"Success"}}`)
+	}}, cfg)
+}
+
+func newCalls() map[string]*int32 {
+	return map[string]*int32{balancePath: new(int32), disbursementPath: new(int32)}
+}
+
+func TestNewClient_ConfigRetryPolicy(t *testing.T) {
+	calls, seen := newCalls(), new(int32)
+	c := flakyClient(&Config{RetryPolicy: fastRetry}, calls, seen)
+
+	if _, err := c.Balance().GetBalance(context.Background()); err != nil {
+		t.Fatalf("balance should succeed after retry: %v", err)
+	}
+	if *calls[balancePath] != 2 {
+		t.Errorf("balance calls = %d, want 2", *calls[balancePath])
+	}
+	// Retry is outermost: Config.Middlewares see every attempt (login + 2 balance attempts).
+	if *seen != 3 {
+		t.Errorf("middleware saw %d requests, want 3", *seen)
+	}
+
+	// CreateDisbursement is exempt from Config.RetryPolicy.
+	if _, err := c.Transfer().CreateDisbursement(context.Background(), safeReq); err == nil {
+		t.Fatal("expected create to fail without retry")
+	}
+	if *calls[disbursementPath] != 1 {
+		t.Errorf("create calls = %d, want 1", *calls[disbursementPath])
+	}
+}
+
+func TestNewClient_OperationRetryPolicies(t *testing.T) {
+	calls, seen := newCalls(), new(int32)
+	c := flakyClient(&Config{OperationRetryPolicies: map[Operation]*RetryPolicy{
+		OperationCreateDisbursement: fastRetry,
+		OperationBalance:            NoRetryPolicy(),
+	}}, calls, seen)
+
+	if _, err := c.Balance().GetBalance(context.Background()); err == nil {
+		t.Fatal("expected balance to fail without retry")
+	}
+	if _, err := c.Transfer().CreateDisbursement(context.Background(), safeReq); err != nil {
+		t.Fatalf("create should succeed after opted-in retry: %v", err)
+	}
+	if *calls[balancePath] != 1 || *calls[disbursementPath] != 2 {
+		t.Errorf("calls: balance=%d create=%d, want 1 and 2", *calls[balancePath], *calls[disbursementPath])
+	}
+}
+
+func TestClient_WithMiddlewareDoesNotRewrap(t *testing.T) {
+	calls, seen := newCalls(), new(int32)
+	c := flakyClient(&Config{RetryPolicy: fastRetry}, calls, seen)
+	c2 := c.WithMiddleware(func(next http.RoundTripper) http.RoundTripper { return next })
+
+	if _, err := c2.Balance().GetBalance(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if *seen != 3 || *calls[balancePath] != 2 {
+		t.Errorf("seen=%d balance calls=%d, want 3 and 2 (middlewares applied once)", *seen, *calls[balancePath])
+	}
+}
+
+func TestClient_WithRetryPolicyReplacesConfigPolicy(t *testing.T) {
+	var balance, create int32
+	c := mockClient(&paytest.API{Handler: func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == disbursementPath {
+			atomic.AddInt32(&create, 1)
+		} else {
+			atomic.AddInt32(&balance, 1)
+		}
+		return paytest.JSON(503, `{"success":false,"message":"unavailable"}`)
+	}}, &Config{RetryPolicy: fastRetry})
+	c2 := c.WithRetryPolicy(&RetryPolicy{MaxRetries: 1, Initial: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	_, _ = c2.Balance().GetBalance(context.Background())
+	if balance != 2 {
+		t.Errorf("balance attempts = %d, want 2 (one retry layer, the clone's policy)", balance)
+	}
+	_, _ = c2.Transfer().CreateDisbursement(context.Background(), safeReq)
+	if create != 1 {
+		t.Errorf("create attempts = %d, want 1", create)
+	}
+}
+
+func TestClient_WithRetryPolicyNilAndNoRetry(t *testing.T) {
+	var n int32
+	c := mockClient(&paytest.API{Handler: func(*http.Request) (*http.Response, error) {
+		atomic.AddInt32(&n, 1)
+		return paytest.JSON(503, `{"success":false,"message":"unavailable"}`)
+	}}, nil)
+	if c.WithRetryPolicy(nil).retry == nil {
+		t.Error("WithRetryPolicy(nil) must install DefaultRetryPolicy, not disable retries")
+	}
+	_, _ = c.WithRetryPolicy(NoRetryPolicy()).Balance().GetBalance(context.Background())
+	if n != 1 {
+		t.Errorf("NoRetryPolicy attempts = %d, want 1", n)
+	}
+}
+
+func TestOperationFromContext(t *testing.T) {
+	if op := OperationFromContext(context.Background()); op != "" {
+		t.Errorf("untagged: %q", op)
+	}
+	ctx := ContextWithOperation(context.Background(), OperationBalance)
+	if op := OperationFromContext(ctx); op != OperationBalance {
+		t.Errorf("got %q", op)
+	}
+}
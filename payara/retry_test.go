@@ -0,0 +1,273 @@
+package payara
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// bodyRecorder returns a RoundTripFunc that records each request body and replies with the given statuses in order.
+func bodyRecorder(t *testing.T, statuses ...int) (func(*http.Request) (*http.Response, error), func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var bodies []string
+	fn := func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		status := statuses[len(statuses)-1]
+		if len(bodies) <= len(statuses) {
+			status = statuses[len(bodies)-1]
+		}
+		mu.Unlock()
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(strings.NewReader(`{"success":true,"message":"ok"}`)),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+		}, nil
+	}
+	return fn, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), bodies...)
+	}
+}
+
+func TestRetryMiddleware_ReplaysJSONBody(t *testing.T) {
+	fn, bodies := bodyRecorder(t, 503, 502, 200)
+	rt := RetryMiddleware(&RetryPolicy{MaxRetries: 3, Initial: time.Millisecond, MaxBackoff: time.Millisecond})(&MockRoundTripper{RoundTripFunc: fn})
+
+	payload := types.CreateDisbursementRequest{ReferenceID: "R1", Amount: 100000, BankCode: "5", AccountNumber: "12330922231", AccountName: "A"}
+	req, err := newJSONRequest(context.Background(), http.MethodPost, "https://test.payara.id/api/v1/disbursement", payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	got := bodies()
+	if len(got) != 3 {
+		t.Fatalf("attempts: got %d, want 3", len(got))
+	}
+	if got[0] == "" {
+		t.Fatal("first attempt sent empty body")
+	}
+	for i, b := range got[1:] {
+		if b != got[0] {
+			t.Errorf("attempt %d body = %q, want %q", i+2, b, got[0])
+		}
+	}
+}
+
+func TestRetryMiddleware_BuffersBodyWithoutGetBody(t *testing.T) {
+	fn, bodies := bodyRecorder(t, 500, 200)
+	rt := RetryMiddleware(&RetryPolicy{MaxRetries: 1, Initial: time.Millisecond})(&MockRoundTripper{RoundTripFunc: fn})
+
+	// io.MultiReader is not one of the types http.NewRequest knows how to rewind, so GetBody stays nil.
+	req, err := http.NewRequest(http.MethodPost, "https://test.payara.id/api/v1/disbursement", io.MultiReader(strings.NewReader(`{"a":1}`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.GetBody != nil {
+		t.Fatal("precondition: GetBody should be nil")
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	got := bodies()
+	if len(got) != 2 || got[0] != `{"a":1}` || got[1] != `{"a":1}` {
+		t.Errorf("bodies: got %q", got)
+	}
+}
+
+func TestClient_DoRequest_ReplaysBodyAfter401(t *testing.T) {
+	loginBody := []byte(`{"success":true,"message":"ok","data":{"access_token":"tok","token_type":"Bearer","expires_in":3600,"merchant_id":"M1","merchant_name":"Test"}}`)
+	disbBody := []byte(`{"success":true,"message":"ok","data":{"transaction_id":"T1","reference_id":"R1","amount":100000,"fee":2500,"total_amount":102500,"status":"PROCESS","bank_code":"5","bank_name":"BCA","account_number":"123","account_name":"A","created_at":"2024-01-01T00:00:00Z"}}`)
+
+	var disbBodies []string
+	mock := &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			status, body := 200, loginBody
+			if req.URL.Path == "/api/v1/disbursement" {
+				b, _ := io.ReadAll(req.Body)
+				disbBodies = append(disbBodies, string(b))
+				body = disbBody
+				if len(disbBodies) == 1 {
+					status, body = 401, []byte(`{"success":false,"message":"expired","error_code":"UNAUTHORIZED"}`)
+				}
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+			}, nil
+		},
+	}
+	client := NewClient(&Config{
+		AppID:      "app",
+		AppSecret:  "secret",
+		BaseURL:    "https://test.payara.id",
+		HTTPClient: &http.Client{Transport: mock},
+	})
+	req := types.CreateDisbursementRequest{ReferenceID: "R1", Amount: 100000, BankCode: "5", AccountNumber: "12330922231", AccountName: "A"}
+	if _, err := client.Transfer().CreateDisbursement(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if len(disbBodies) != 2 {
+		t.Fatalf("disbursement attempts: got %d, want 2", len(disbBodies))
+	}
+	if disbBodies[0] == "" || disbBodies[1] != disbBodies[0] {
+		t.Errorf("bodies differ: first=%q second=%q", disbBodies[0], disbBodies[1])
+	}
+}
+
+func TestRetryMiddleware_CancelAbortsBackoff(t *testing.T) {
+	rt := RetryMiddleware(&RetryPolicy{MaxRetries: 3, Initial: 30 * time.Second, MaxBackoff: 30 * time.Second})(&MockRoundTripper{StatusCode: 503})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://test.payara.id/api/v1/balance", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = rt.RoundTrip(req)
+	if err != context.Canceled {
+		t.Fatalf("err: got %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("backoff not aborted: took %v", elapsed)
+	}
+}
+
+func TestRetryRoundTripper_JitterBounds(t *testing.T) {
+	base := &retryRoundTripper{initial: 100 * time.Millisecond, maxBackoff: time.Second}
+	tests := []struct {
+		name   string
+		jitter Jitter
+		rnd    func(int64) int64
+		prev   time.Duration
+		want   time.Duration
+	}{
+		{"none", JitterNone, nil, 0, 400 * time.Millisecond},
+		{"full min", JitterFull, func(int64) int64 { return 0 }, 0, 0},
+		{"full max", JitterFull, func(n int64) int64 { return n - 1 }, 0, 400 * time.Millisecond},
+		{"equal min", JitterEqual, func(int64) int64 { return 0 }, 0, 200 * time.Millisecond},
+		{"equal max", JitterEqual, func(n int64) int64 { return n - 1 }, 0, 400 * time.Millisecond},
+		{"decorrelated min", JitterDecorrelated, func(int64) int64 { return 0 }, 200 * time.Millisecond, 100 * time.Millisecond},
+		{"decorrelated max", JitterDecorrelated, func(n int64) int64 { return n - 1 }, 200 * time.Millisecond, 600 * time.Millisecond},
+		{"decorrelated capped", JitterDecorrelated, func(n int64) int64 { return n - 1 }, 900 * time.Millisecond, time.Second},
+	}
+	for _, tt := range tests {
+		r := *base
+		r.jitter = tt.jitter
+		r.randInt63n = tt.rnd
+		if got := r.wait(400*time.Millisecond, tt.prev); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryMiddleware_429HonorsRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		body   string
+	}{
+		{"header", "0", `{"success":false,"message":"slow down","error_code":"RATE_LIMITED"}`},
+		{"meta", "", `{"success":false,"message":"slow down","error_code":"RATE_LIMITED","meta":{"retry_after":0}}`},
+	}
+	for _, tt := range tests {
+		var calls int
+		mock := &MockRoundTripper{RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				h := http.Header{}
+				if tt.header != "" {
+					h.Set("Retry-After", tt.header)
+				}
+				return &http.Response{StatusCode: 429, Header: h, Body: io.NopCloser(strings.NewReader(tt.body))}, nil
+			}
+			return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+		}}
+		// Initial backoff is long so the test only passes quickly if the server hint (0s) is used.
+		rt := RetryMiddleware(&RetryPolicy{MaxRetries: 2, Initial: 30 * time.Second})(mock)
+		req, _ := http.NewRequest(http.MethodGet, "https://test.payara.id/api/v1/balance", nil)
+		start := time.Now()
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != 200 || calls != 2 {
+			t.Errorf("%s: status=%d calls=%d", tt.name, resp.StatusCode, calls)
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Errorf("%s: Retry-After hint not used", tt.name)
+		}
+	}
+}
+
+func TestRetryMiddleware_429BeyondCapReturnsResponse(t *testing.T) {
+	var calls int
+	mock := &MockRoundTripper{RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: 429,
+			Header:     http.Header{"Retry-After": []string{"600"}},
+			Body:       io.NopCloser(strings.NewReader(`{"success":false,"message":"slow down"}`)),
+		}, nil
+	}}
+	rt := RetryMiddleware(&RetryPolicy{MaxRetries: 3, MaxRetryAfter: time.Second})(mock)
+	req, _ := http.NewRequest(http.MethodGet, "https://test.payara.id/api/v1/balance", nil)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 429 || calls != 1 {
+		t.Errorf("status=%d calls=%d", resp.StatusCode, calls)
+	}
+	if b, _ := io.ReadAll(resp.Body); !strings.Contains(string(b), "slow down") {
+		t.Errorf("body not preserved: %q", b)
+	}
+}
+
+func TestBalanceService_RateLimitError(t *testing.T) {
+	loginBody := []byte(`{"success":true,"message":"ok","data":{"access_token":"tok","token_type":"Bearer","expires_in":3600,"merchant_id":"M1","merchant_name":"Test"}}`)
+	mock := &MockRoundTripper{RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/api/v1/login" {
+			return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(loginBody))}, nil
+		}
+		return &http.Response{
+			StatusCode: 429,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"success":false,"message":"Too many requests","error_code":"RATE_LIMITED","meta":{"retry_after":42}}`)),
+		}, nil
+	}}
+	client := NewClient(&Config{AppID: "a", AppSecret: "b", BaseURL: "https://test.payara.id", HTTPClient: &http.Client{Transport: mock}})
+	_, err := client.Balance().GetBalance(context.Background())
+	var rl *RateLimitError
+	if !errors.As(err, &rl) {
+		t.Fatalf("expected *RateLimitError, got %v", err)
+	}
+	if rl.RetryAfter != 42*time.Second {
+		t.Errorf("RetryAfter: got %v", rl.RetryAfter)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "RATE_LIMITED" || apiErr.HTTPStatus != 429 {
+		t.Errorf("wrapped APIError: got %+v", apiErr)
+	}
+}
@@ -0,0 +1,291 @@
+package payara
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// StoredToken is an access token shared through a TokenStore.
+type StoredToken struct {
+	AccessToken string    `json:"access_token"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+// TokenStore shares access tokens between clients and processes so they reuse one login instead of
+// each calling POST /api/v1/login. Keys are derived from base URL and app ID (see TokenKey).
+//
+// Lock serialises refreshes for a key across everything sharing the store: the holder logs in and
+// saves, the others then load its token. Implementations must be safe for concurrent use.
+type TokenStore interface {
+	// Load returns the token for key, or nil (and no error) when none is stored.
+	Load(ctx context.Context, key string) (*StoredToken, error)
+	Save(ctx context.Context, key string, tok StoredToken) error
+	// Lock blocks until the refresh lock for key is held or ctx is done. unlock must be called once.
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+}
+
+// TokenKey returns the store key for credentials of appID against baseURL. The app ID is hashed so it
+// does not appear in file names or Redis keys.
+func TokenKey(baseURL, appID string) string {
+	sum := sha256.Sum256([]byte(baseURL + "\x00" + appID))
+	return "payara-token-" + hex.EncodeToString(sum[:12])
+}
+
+// storeFreshness is how long a stored token must remain valid to be reused: past the expiry buffer
+// and the early refresh lead, so reusing it never triggers an immediate refresh.
+const storeFreshness = tokenBuffer + refreshLead
+
+// tokenFromStore wraps fetch with store lookup and lock-coordinated refresh. Store failures are
+// logged and fall back to a direct login; the store is an optimisation, never a hard dependency.
+// A stored token equal to stale (just rejected by the API) is never reused.
+func tokenFromStore(store TokenStore, key string, logger Logger, fetch func(context.Context, *http.Client) (string, time.Time, error)) tokenFetcher {
+	return func(ctx context.Context, hc *http.Client, stale string) (string, time.Time, error) {
+		fresh := func(t *StoredToken) bool {
+			return t != nil && t.AccessToken != "" && t.AccessToken != stale && time.Now().Add(storeFreshness).Before(t.Expiry)
+		}
+		if tok, err := store.Load(ctx, key); err != nil {
+			logger.Warn("payara token store load failed", "error", err)
+		} else if fresh(tok) {
+			return tok.AccessToken, tok.Expiry, nil
+		}
+
+		unlock, err := store.Lock(ctx, key)
+		if err != nil {
+			logger.Warn("payara token store lock failed; logging in without it", "error", err)
+			return fetch(ctx, hc)
+		}
+		defer unlock()
+		// Another holder may have refreshed while we waited.
+		if tok, err := store.Load(ctx, key); err == nil && fresh(tok) {
+			return tok.AccessToken, tok.Expiry, nil
+		}
+		token, expiry, err := fetch(ctx, hc)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		if err := store.Save(ctx, key, StoredToken{AccessToken: token, Expiry: expiry}); err != nil {
+			logger.Warn("payara token store save failed", "error", err)
+		}
+		return token, expiry, nil
+	}
+}
+
+// MemoryTokenStore is an in-process TokenStore. Share one instance between clients (e.g. one per
+// tenant in a multi-client service) so they log in once.
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]StoredToken
+	locks  map[string]chan struct{}
+}
+
+// NewMemoryTokenStore returns an empty in-memory store.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string]StoredToken), locks: make(map[string]chan struct{})}
+}
+
+// Load implements TokenStore.
+func (s *MemoryTokenStore) Load(_ context.Context, key string) (*StoredToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tok, ok := s.tokens[key]
+	if !ok {
+		return nil, nil
+	}
+	return &tok, nil
+}
+
+// Save implements TokenStore.
+func (s *MemoryTokenStore) Save(_ context.Context, key string, tok StoredToken) error {
+	s.mu.Lock()
+	s.tokens[key] = tok
+	s.mu.Unlock()
+	return nil
+}
+
+// Lock implements TokenStore.
+func (s *MemoryTokenStore) Lock(ctx context.Context, key string) (func(), error) {
+	s.mu.Lock()
+	sem, ok := s.locks[key]
+	if !ok {
+		sem = make(chan struct{}, 1)
+		s.locks[key] = sem
+	}
+	s.mu.Unlock()
+	select {
+	case sem <- struct{}{}:
+		var once sync.Once
+		return func() { once.Do(func() { <-sem }) }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// FileTokenStore keeps tokens as JSON files in a directory, shared by processes on one host
+// (or a shared volume). Files are written atomically with mode 0600. The refresh lock is a
+// "<key>.lock" file created exclusively; locks older than LockTTL are treated as abandoned.
+type FileTokenStore struct {
+	dir string
+	// LockTTL bounds how long a crashed holder can block others. Default 1 minute.
+	LockTTL time.Duration
+	// PollInterval is how often Lock retries while the lock is held elsewhere. Default 50ms.
+	PollInterval time.Duration
+}
+
+// NewFileTokenStore returns a store in dir, creating it (0700) if needed.
+func NewFileTokenStore(dir string) (*FileTokenStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileTokenStore{dir: dir, LockTTL: time.Minute, PollInterval: 50 * time.Millisecond}, nil
+}
+
+// Load implements TokenStore.
+func (s *FileTokenStore) Load(_ context.Context, key string) (*StoredToken, error) {
+	b, err := os.ReadFile(filepath.Join(s.dir, key+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tok StoredToken
+	if err := json.Unmarshal(b, &tok); err != nil {
+		return nil, err
+	}
+	return &tok, nil
+}
+
+// Save implements TokenStore.
+func (s *FileTokenStore) Save(_ context.Context, key string, tok StoredToken) error {
+	b, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(s.dir, key+".json"))
+}
+
+// Lock implements TokenStore.
+func (s *FileTokenStore) Lock(ctx context.Context, key string) (func(), error) {
+	path := filepath.Join(s.dir, key+".lock")
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+			var once sync.Once
+			return func() { once.Do(func() { os.Remove(path) }) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if fi, err := os.Stat(path); err == nil && s.LockTTL > 0 && time.Since(fi.ModTime()) > s.LockTTL {
+			os.Remove(path) // abandoned by a crashed holder
+			continue
+		}
+		select {
+		case <-time.After(s.PollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// RedisClient is the subset of Redis commands RedisTokenStore needs. Adapt your Redis library
+// (go-redis, redigo, rueidis) with a few lines; see README. Get returns ok=false for a missing key.
+type RedisClient interface {
+	Get(ctx context.Context, key string) (value string, ok bool, err error)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// SetNX sets key only if absent (SET key value NX PX ttl) and reports whether it did.
+	SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error)
+	// DelIfEqual deletes key only if it still holds value (a compare-and-delete Lua script).
+	DelIfEqual(ctx context.Context, key, value string) error
+}
+
+// RedisTokenStore shares tokens between pods through Redis. Tokens are stored with a TTL matching
+// their expiry; the refresh lock is SET NX with LockTTL and a random owner value.
+type RedisTokenStore struct {
+	client RedisClient
+	prefix string
+	// LockTTL bounds how long a crashed holder can block others. Default 1 minute.
+	LockTTL time.Duration
+	// PollInterval is how often Lock retries while the lock is held elsewhere. Default 50ms.
+	PollInterval time.Duration
+}
+
+// NewRedisTokenStore returns a store using client. prefix namespaces keys (e.g. "myapp:").
+func NewRedisTokenStore(client RedisClient, prefix string) *RedisTokenStore {
+	return &RedisTokenStore{client: client, prefix: prefix, LockTTL: time.Minute, PollInterval: 50 * time.Millisecond}
+}
+
+// Load implements TokenStore.
+func (s *RedisTokenStore) Load(ctx context.Context, key string) (*StoredToken, error) {
+	v, ok, err := s.client.Get(ctx, s.prefix+key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var tok StoredToken
+	if err := json.Unmarshal([]byte(v), &tok); err != nil {
+		return nil, err
+	}
+	return &tok, nil
+}
+
+// Save implements TokenStore.
+func (s *RedisTokenStore) Save(ctx context.Context, key string, tok StoredToken) error {
+	ttl := time.Until(tok.Expiry)
+	if ttl <= 0 {
+		return nil
+	}
+	b, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+key, string(b), ttl)
+}
+
+// Lock implements TokenStore.
+func (s *RedisTokenStore) Lock(ctx context.Context, key string) (func(), error) {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return nil, err
+	}
+	owner, lockKey := hex.EncodeToString(buf[:]), s.prefix+key+":lock"
+	for {
+		ok, err := s.client.SetNX(ctx, lockKey, owner, s.LockTTL)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			var once sync.Once
+			return func() {
+				once.Do(func() { _ = s.client.DelIfEqual(context.WithoutCancel(ctx), lockKey, owner) })
+			}, nil
+		}
+		select {
+		case <-time.After(s.PollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package payara
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/internal/paytest"
+)
+
+// fakeRedis is an in-memory RedisClient with TTLs, standing in for a real server.
+type fakeRedis struct {
+	mu   sync.Mutex
+	vals map[string]string
+	exp  map[string]time.Time
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{vals: map[string]string{}, exp: map[string]time.Time{}}
+}
+
+func (r *fakeRedis) getLocked(key string) (string, bool) {
+	if e, ok := r.exp[key]; ok && time.Now().After(e) {
+		delete(r.vals, key)
+		delete(r.exp, key)
+	}
+	v, ok := r.vals[key]
+	return v, ok
+}
+
+func (r *fakeRedis) Get(_ context.Context, key string) (string, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.getLocked(key)
+	return v, ok, nil
+}
+
+func (r *fakeRedis) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.vals[key], r.exp[key] = value, time.Now().Add(ttl)
+	return nil
+}
+
+func (r *fakeRedis) SetNX(_ context.Context, key, value string, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.getLocked(key); ok {
+		return false, nil
+	}
+	r.vals[key], r.exp[key] = value, time.Now().Add(ttl)
+	return true, nil
+}
+
+func (r *fakeRedis) DelIfEqual(_ context.Context, key, value string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.getLocked(key); ok && v == value {
+		delete(r.vals, key)
+		delete(r.exp, key)
+	}
+	return nil
+}
+
+// storeClient returns a client using store whose login calls increment logins.
+// Balance calls accept any token except "revoked".
+func storeClient(store TokenStore, logins *int32, tokens ...string) *Client {
+	return mockClient(&paytest.API{
+		Login: func(*http.Request, int) (*http.Response, error) {
+			n := atomic.AddInt32(logins, 1)
+			tok := "tok"
+			if int(n) <= len(tokens) {
+				tok = tokens[n-1]
+			}
+			return paytest.Login(tok, 3600)
+		},
+		Handler: func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") == "Bearer revoked" {
+				return paytest.JSON(401, `{"success":false,"message":"invalid token"}`)
+			}
+			return paytest.JSON(200, authBalanceBody)
+		},
+	}, &Config{TokenStore: store})
+}
+
+func TestTokenStore_ClientsShareOneLogin(t *testing.T) {
+	dir := t.TempDir()
+	fileStore, err := NewFileTokenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := map[string]func() TokenStore{
+		"memory": func() TokenStore { return NewMemoryTokenStore() },
+		"file":   func() TokenStore { return fileStore },
+		"redis":  func() TokenStore { return NewRedisTokenStore(newFakeRedis(), "test:") },
+	}
+	for name, mk := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := mk()
+			var logins int32
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					c := storeClient(store, &logins)
+					if _, err := c.Balance().GetBalance(context.Background()); err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+			wg.Wait()
+			if logins != 1 {
+				t.Errorf("logins = %d, want 1", logins)
+			}
+		})
+	}
+}
+
+func TestTokenStore_RejectedStoredTokenIsReplaced(t *testing.T) {
+	store := NewMemoryTokenStore()
+	key := TokenKey("https://test.payara.id", "a")
+	_ = store.Save(context.Background(), key, StoredToken{AccessToken: "revoked", Expiry: time.Now().Add(time.Hour)})
+
+	var logins int32
+	c := storeClient(store, &logins, "fresh")
+	if _, err := c.Balance().GetBalance(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if logins != 1 {
+		t.Errorf("logins = %d, want 1", logins)
+	}
+	tok, _ := store.Load(context.Background(), key)
+	if tok == nil || tok.AccessToken != "fresh" {
+		t.Errorf("stored token = %+v, want fresh", tok)
+	}
+}
+
+func TestTokenStore_ExpiringTokenNotReused(t *testing.T) {
+	store := NewMemoryTokenStore()
+	key := TokenKey("https://test.payara.id", "a")
+	_ = store.Save(context.Background(), key, StoredToken{AccessToken: "old", Expiry: time.Now().Add(tokenBuffer)})
+
+	var logins int32
+	c := storeClient(store, &logins)
+	if _, err := c.Balance().GetBalance(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if logins != 1 {
+		t.Errorf("logins = %d, want 1", logins)
+	}
+}
+
+func TestTokenKey_ScopedToBaseURLAndApp(t *testing.T) {
+	k := TokenKey("https://a", "app")
+	if k == TokenKey("https://b", "app") || k == TokenKey("https://a", "other") {
+		t.Error("keys must differ per base URL and app ID")
+	}
+}
+
+func TestFileTokenStore_Lock(t *testing.T) {
+	s, err := NewFileTokenStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.PollInterval = 5 * time.Millisecond
+	unlock, err := s.Lock(context.Background(), "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := s.Lock(ctx, "k"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline while held, got %v", err)
+	}
+	unlock()
+	unlock2, err := s.Lock(context.Background(), "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock2()
+
+	// A lock left by a crashed holder is reclaimed after LockTTL.
+	path := filepath.Join(s.dir, "k.lock")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Minute)
+	_ = os.Chtimes(path, old, old)
+	ctx2, cancel2 := context.WithTimeout(context.Background(), time.Second)
+	defer cancel2()
+	unlock3, err := s.Lock(ctx2, "k")
+	if err != nil {
+		t.Fatalf("stale lock not reclaimed: %v", err)
+	}
+	unlock3()
+}
+
+func TestRedisTokenStore_LockOwnership(t *testing.T) {
+	r := newFakeRedis()
+	s := NewRedisTokenStore(r, "p:")
+	unlock, err := s.Lock(context.Background(), "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Simulate the lock expiring and another owner taking it; our unlock must not release theirs.
+	_ = r.Set(context.Background(), "p:k:lock", "someone-else", time.Minute)
+	unlock()
+	if v, ok, _ := r.Get(context.Background(), "p:k:lock"); !ok || v != "someone-else" {
+		t.Errorf("unlock released a lock it did not own: %q %v", v, ok)
+	}
+}
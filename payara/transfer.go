@@ -2,9 +2,9 @@ package payara
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/turahe/payara-go-sdk/payara/types"
 )
@@ -16,55 +16,40 @@ const (
 
 // CreateDisbursement sends POST /api/v1/disbursement.
 // Amount is in IDR whole units (min 10_000, max 50_000_000). reference_id must be unique.
+// The request is checked with req.ValidateWith(Config.Destinations) first (returns *types.ValidationError)
+// unless Config.SkipValidation is set.
 func (s *transferService) CreateDisbursement(ctx context.Context, req types.CreateDisbursementRequest) (*types.CreateDisbursementResponse, error) {
-	httpReq, err := newJSONRequest(ctx, http.MethodPost, s.client.baseURL+disbursementPath, req)
-	if err != nil {
-		return nil, err
+	if !s.client.skipValidation {
+		if err := req.ValidateWith(s.client.destinations); err != nil {
+			return nil, err
+		}
 	}
-	resp, err := s.client.doRequest(ctx, httpReq)
+	ctx = ContextWithOperation(ctx, OperationCreateDisbursement)
+	resp, err := Do[types.CreateDisbursementResponseData](ctx, s.client, http.MethodPost, disbursementPath, req)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	raw, _ := readAll(resp.Body)
-	var out types.CreateDisbursementResponse
-	if err := json.Unmarshal(raw, &out); err != nil {
-		return nil, parseErrorResponse(raw, resp.StatusCode)
-	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, parseErrorResponse(raw, resp.StatusCode)
-	}
-	if !out.Success {
-		return nil, parseErrorResponse(raw, resp.StatusCode)
+	if obs, ok := s.client.fees.(FeeObserver); ok && resp.Data != nil {
+		obs.ObserveFee(req.BankCode, req.Amount, resp.Data.Fee)
 	}
-	return &out, nil
+	return resp, nil
 }
 
 // GetDisbursementStatus sends GET /api/v1/check-status/{id}. Doc: Check Status.
 // id can be transaction_id (path) or use GetDisbursementStatusByReference for reference_id (query param).
 func (s *transferService) GetDisbursementStatus(ctx context.Context, id string) (*types.DisbursementStatusResponse, error) {
-	url := s.client.baseURL + checkStatusPath + "/" + id
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := s.client.doRequest(ctx, httpReq)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	raw, _ := readAll(resp.Body)
-	var out types.DisbursementStatusResponse
-	if err := json.Unmarshal(raw, &out); err != nil {
-		return nil, parseErrorResponse(raw, resp.StatusCode)
-	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, parseErrorResponse(raw, resp.StatusCode)
-	}
-	if !out.Success {
-		return nil, parseErrorResponse(raw, resp.StatusCode)
-	}
-	return &out, nil
+	ctx = ContextWithOperation(ctx, OperationDisbursementStatus)
+	return Do[types.DisbursementStatusData](ctx, s.client, http.MethodGet, checkStatusPath+"/"+url.PathEscape(id), nil)
+}
+
+// GetDisbursementStatusByReference sends GET /api/v1/check-status?reference_id={referenceID}. Doc: Check Status.
+// Use it to recover a transaction when only the merchant reference_id is known (e.g. after a crash before
+// CreateDisbursement returned the transaction_id).
+func (s *transferService) GetDisbursementStatusByReference(ctx context.Context, referenceID string) (*types.DisbursementStatusResponse, error) {
+	q := url.Values{}
+	q.Set("reference_id", referenceID)
+	ctx = ContextWithOperation(ctx, OperationDisbursementStatus)
+	return Do[types.DisbursementStatusData](ctx, s.client, http.MethodGet, checkStatusPath+"?"+q.Encode(), nil)
 }
 
 // ListDisbursement is not implemented. Payara API 1.0 docs do not document a list disbursement endpoint.
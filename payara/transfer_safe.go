@@ -0,0 +1,113 @@
+package payara
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// SafeDisbursementOptions configures CreateDisbursementSafely. Nil uses the defaults.
+type SafeDisbursementOptions struct {
+	MaxAttempts int           // Max create attempts including resubmits (default 3)
+	LookupDelay time.Duration // Wait before checking status after an ambiguous failure (default 2s)
+}
+
+// CreateDisbursementSafely creates a disbursement without risking a double payout.
+// On an ambiguous failure (network error, timeout or 5xx after the request may have been sent) it looks up
+// req.ReferenceID via check-status and:
+//   - returns the existing transaction if the lookup finds it;
+//   - resubmits only if the lookup proves the transfer does not exist (a not-found error_code from Payara);
+//   - otherwise returns *DisbursementUnknownError without resubmitting.
+//
+// A duplicate reference_id rejection is also resolved by returning the existing transaction.
+// Definite failures (4xx validation, insufficient balance, 429, a decoded success=false envelope) are returned as-is.
+func (s *transferService) CreateDisbursementSafely(ctx context.Context, req types.CreateDisbursementRequest, opts *SafeDisbursementOptions) (*types.CreateDisbursementResponse, error) {
+	maxAttempts, lookupDelay := 3, 2*time.Second
+	if opts != nil {
+		if opts.MaxAttempts > 0 {
+			maxAttempts = opts.MaxAttempts
+		}
+		if opts.LookupDelay > 0 {
+			lookupDelay = opts.LookupDelay
+		}
+	}
+	var createErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		resp, err := s.CreateDisbursement(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		createErr = err
+		duplicate := isDuplicateReference(err)
+		if !duplicate && !isAmbiguousCreateError(err) {
+			return nil, err
+		}
+		if !duplicate {
+			if err := sleep(ctx, lookupDelay); err != nil {
+				return nil, &DisbursementUnknownError{ReferenceID: req.ReferenceID, CreateErr: createErr, LookupErr: err}
+			}
+		}
+		status, lookupErr := s.GetDisbursementStatusByReference(ctx, req.ReferenceID)
+		if lookupErr == nil && status.Data != nil {
+			return createResponseFromStatus(status), nil
+		}
+		if lookupErr == nil || !IsNotFound(lookupErr) || duplicate {
+			return nil, &DisbursementUnknownError{ReferenceID: req.ReferenceID, CreateErr: createErr, LookupErr: lookupErr}
+		}
+		// Provably not created: safe to resubmit.
+	}
+	return nil, createErr
+}
+
+// isAmbiguousCreateError reports whether a create failure leaves the transfer outcome unknown.
+func isAmbiguousCreateError(err error) bool {
+	var rl *RateLimitError
+	var ve *types.ValidationError
+	if errors.As(err, &rl) || errors.As(err, &ve) {
+		return false
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		// Transport error or context expiry: the request may have reached Payara.
+		return true
+	}
+	// A 2xx whose body could not be decoded, timeouts and server errors are ambiguous. A decoded 2xx
+	// success=false envelope and other 4xx are definite rejections.
+	if apiErr.HTTPStatus >= 200 && apiErr.HTTPStatus < 300 {
+		return apiErr.Err != nil
+	}
+	return apiErr.HTTPStatus == 0 || apiErr.HTTPStatus == http.StatusRequestTimeout || apiErr.HTTPStatus >= 500
+}
+
+// isDuplicateReference reports whether Payara rejected the request because reference_id already exists.
+func isDuplicateReference(err error) bool {
+	return errors.Is(err, ErrDuplicateReference)
+}
+
+// createResponseFromStatus converts a check-status result into the create response shape.
+func createResponseFromStatus(st *types.DisbursementStatusResponse) *types.CreateDisbursementResponse {
+	d := st.Data
+	return &types.CreateDisbursementResponse{
+		Success: st.Success,
+		Message: st.Message,
+		Meta:    st.Meta,
+		Data: &types.CreateDisbursementResponseData{
+			TransactionID: d.TransactionID,
+			ReferenceID:   d.ReferenceID,
+			Amount:        d.Amount,
+			Fee:           d.Fee,
+			TotalAmount:   d.TotalAmount,
+			Status:        d.Status,
+			BankCode:      d.BankCode,
+			BankName:      d.BankName,
+			AccountNumber: d.AccountNumber,
+			AccountName:   d.AccountName,
+			Description:   d.Description,
+			CreatedAt:     d.CreatedAt,
+			FailureReason: d.FailureReason,
+		},
+	}
+}
@@ -0,0 +1,166 @@
+package payara
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/internal/paytest"
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+type safeStep struct {
+	status int
+	body   string
+	err    error
+}
+
+// safeMock serves login and replays the given create / check-status steps in order.
+func safeMock(t *testing.T, creates, lookups []safeStep) (*Client, *int, *int) {
+	t.Helper()
+	var nCreate, nLookup int
+	next := func(steps []safeStep, n *int) (*http.Response, error) {
+		if *n >= len(steps) {
+			t.Fatalf("unexpected call #%d", *n+1)
+		}
+		st := steps[*n]
+		*n++
+		if st.err != nil {
+			return nil, st.err
+		}
+		return paytest.JSON(st.status, st.body)
+	}
+	client := mockClient(&paytest.API{Handler: func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/api/v1/disbursement":
+			return next(creates, &nCreate)
+		case "/api/v1/check-status":
+			if req.URL.Query().Get("reference_id") != "R1" {
+				t.Errorf("lookup reference_id: got %q", req.URL.Query().Get("reference_id"))
+			}
+			return next(lookups, &nLookup)
+		}
+		t.Fatalf("unexpected path: %s", req.URL.Path)
+		return nil, nil
+	}}, nil)
+	return client, &nCreate, &nLookup
+}
+
+var (
+	safeReq       = types.CreateDisbursementRequest{ReferenceID: "R1", Amount: 100000, BankCode: "5", AccountNumber: "12330922231", AccountName: "A"}
+	safeOpts      = &SafeDisbursementOptions{LookupDelay: time.Millisecond}
+	createdBody   = `{"success":true,"message":"ok","data":{"transaction_id":"T2","reference_id":"R1","amount":100000,"fee":2500,"total_amount":102500,"status":"PROCESS"}}`
+	existingBody  = `{"success":true,"message":"ok","data":{"transaction_id":"T1","reference_id":"R1","status":"PROCESS","amount":100000,"fee":2500,"total_amount":102500}}`
+	notFoundBody  = `{"success":false,"message":"Transaction not found","error_code":"TRANSACTION_NOT_FOUND"}`
+	serverErrBody = `{"success":false,"message":"internal error","error_code":"INTERNAL_ERROR"}`
+)
+
+func TestCreateDisbursementSafely_ExistingAfter5xx(t *testing.T) {
+	client, nCreate, _ := safeMock(t,
+		[]safeStep{{status: 503, body: serverErrBody}},
+		[]safeStep{{status: 200, body: existingBody}})
+	resp, err := client.Transfer().CreateDisbursementSafely(context.Background(), safeReq, safeOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.TransactionID != "T1" || resp.Data.TotalAmount != 102500 {
+		t.Errorf("expected existing transaction, got %+v", resp.Data)
+	}
+	if *nCreate != 1 {
+		t.Errorf("create calls: got %d, want 1", *nCreate)
+	}
+}
+
+func TestCreateDisbursementSafely_ResubmitsWhenNotFound(t *testing.T) {
+	client, nCreate, _ := safeMock(t,
+		[]safeStep{{err: io.ErrUnexpectedEOF}, {status: 200, body: createdBody}},
+		[]safeStep{{status: 404, body: notFoundBody}})
+	resp, err := client.Transfer().CreateDisbursementSafely(context.Background(), safeReq, safeOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.TransactionID != "T2" || *nCreate != 2 {
+		t.Errorf("txn=%s creates=%d", resp.Data.TransactionID, *nCreate)
+	}
+}
+
+func TestCreateDisbursementSafely_UnknownWhenLookupFails(t *testing.T) {
+	client, nCreate, _ := safeMock(t,
+		[]safeStep{{err: io.ErrUnexpectedEOF}},
+		[]safeStep{{status: 502, body: serverErrBody}})
+	_, err := client.Transfer().CreateDisbursementSafely(context.Background(), safeReq, safeOpts)
+	var unk *DisbursementUnknownError
+	if !errors.As(err, &unk) {
+		t.Fatalf("expected *DisbursementUnknownError, got %v", err)
+	}
+	if unk.ReferenceID != "R1" || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got %+v", unk)
+	}
+	if *nCreate != 1 {
+		t.Errorf("must not resubmit: creates=%d", *nCreate)
+	}
+}
+
+func TestCreateDisbursementSafely_Bare404IsUnknown(t *testing.T) {
+	for name, body := range map[string]string{
+		"html":          `<html>404 Not Found</html>`,
+		"no error_code": `{"success":false,"message":"route not found"}`,
+	} {
+		client, nCreate, _ := safeMock(t,
+			[]safeStep{{err: io.ErrUnexpectedEOF}},
+			[]safeStep{{status: 404, body: body}})
+		_, err := client.Transfer().CreateDisbursementSafely(context.Background(), safeReq, safeOpts)
+		var unk *DisbursementUnknownError
+		if !errors.As(err, &unk) {
+			t.Errorf("%s: expected *DisbursementUnknownError, got %v", name, err)
+		}
+		if *nCreate != 1 {
+			t.Errorf("%s: must not resubmit: creates=%d", name, *nCreate)
+		}
+	}
+}
+
+func TestCreateDisbursementSafely_DuplicateReturnsExisting(t *testing.T) {
+	client, _, _ := safeMock(t,
+		[]safeStep{{status: 409, body: `{"success":false,"message":"Duplicate reference","error_code":"DUPLICATE_REFERENCE"}`}},
+		[]safeStep{{status: 200, body: existingBody}})
+	resp, err := client.Transfer().CreateDisbursementSafely(context.Background(), safeReq, safeOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.TransactionID != "T1" {
+		t.Errorf("got %+v", resp.Data)
+	}
+}
+
+func TestCreateDisbursementSafely_DefiniteFailureNoLookup(t *testing.T) {
+	for _, status := range []int{400, 200} {
+		client, nCreate, nLookup := safeMock(t,
+			[]safeStep{{status: status, body: `{"success":false,"message":"Insufficient balance","error_code":"INSUFFICIENT_BALANCE"}`}},
+			nil)
+		_, err := client.Transfer().CreateDisbursementSafely(context.Background(), safeReq, safeOpts)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != "INSUFFICIENT_BALANCE" {
+			t.Fatalf("HTTP %d: got %v", status, err)
+		}
+		if *nCreate != 1 || *nLookup != 0 {
+			t.Errorf("HTTP %d: creates=%d lookups=%d, want 1 and 0", status, *nCreate, *nLookup)
+		}
+	}
+}
+
+func TestCreateDisbursementSafely_UndecodableSuccessIsAmbiguous(t *testing.T) {
+	client, nCreate, nLookup := safeMock(t,
+		[]safeStep{{status: 200, body: `<html>gateway</html>`}},
+		[]safeStep{{status: 200, body: existingBody}})
+	resp, err := client.Transfer().CreateDisbursementSafely(context.Background(), safeReq, safeOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.TransactionID != "T1" || *nCreate != 1 || *nLookup != 1 {
+		t.Errorf("got %+v creates=%d lookups=%d", resp.Data, *nCreate, *nLookup)
+	}
+}
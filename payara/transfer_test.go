@@ -3,6 +3,7 @@ package payara
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"testing"
@@ -46,7 +47,7 @@ func TestTransferService_CreateDisbursement_Mock(t *testing.T) {
 		ReferenceID:   "R1",
 		Amount:        100000,
 		BankCode:      "5",
-		AccountNumber: "123",
+		AccountNumber: "12330922231",
 		AccountName:   "A",
 	}
 	resp, err := client.Transfer().CreateDisbursement(ctx, req)
@@ -60,3 +61,72 @@ func TestTransferService_CreateDisbursement_Mock(t *testing.T) {
 		t.Errorf("expected at least login + disbursement calls, got %d", callCount)
 	}
 }
+
+func TestTransferService_GetDisbursementStatusByReference_Mock(t *testing.T) {
+	loginBody := []byte(`{"success":true,"message":"ok","data":{"access_token":"tok","token_type":"Bearer","expires_in":3600,"merchant_id":"M1","merchant_name":"Test"}}`)
+	statusBody := []byte(`{"success":true,"message":"ok","data":{"transaction_id":"T1","reference_id":"REF 1/A","status":"SUCCESS","amount":100000,"fee":2500,"total_amount":102500,"bank_code":"5","bank_name":"BCA","account_number":"123","account_name":"A","created_at":"2024-01-01T00:00:00Z"}}`)
+
+	mock := &MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			var body []byte
+			switch req.URL.Path {
+			case "/api/v1/login":
+				body = loginBody
+			case "/api/v1/check-status":
+				if req.Method != http.MethodGet {
+					t.Errorf("method: got %s", req.Method)
+				}
+				if got := req.URL.Query().Get("reference_id"); got != "REF 1/A" {
+					t.Errorf("reference_id query: got %q", got)
+				}
+				body = statusBody
+			default:
+				t.Fatalf("unexpected path: %s", req.URL.Path)
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+			}, nil
+		},
+	}
+	client := NewClient(&Config{
+		AppID:      "app",
+		AppSecret:  "secret",
+		BaseURL:    "https://test.payara.id",
+		HTTPClient: &http.Client{Transport: mock},
+	})
+	resp, err := client.Transfer().GetDisbursementStatusByReference(context.Background(), "REF 1/A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data == nil || resp.Data.TransactionID != "T1" || resp.Data.Status != types.DisbursementStatusSuccess {
+		t.Errorf("unexpected data: %+v", resp.Data)
+	}
+}
+
+func TestTransferService_CreateDisbursement_ValidationBeforeSend(t *testing.T) {
+	var calls int
+	mock := &MockRoundTripper{RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: 500, Body: io.NopCloser(bytes.NewReader(nil)), Header: http.Header{}}, nil
+	}}
+	client := NewClient(&Config{AppID: "app", AppSecret: "secret", BaseURL: "https://test.payara.id", HTTPClient: &http.Client{Transport: mock}})
+	_, err := client.Transfer().CreateDisbursement(context.Background(), types.CreateDisbursementRequest{ReferenceID: "R1", Amount: 5000, BankCode: "5", AccountNumber: "12330922231", AccountName: "A"})
+	var ve *types.ValidationError
+	if !errors.As(err, &ve) || len(ve.Field("amount")) != 1 {
+		t.Fatalf("expected amount ValidationError, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("no HTTP call expected, got %d", calls)
+	}
+
+	skip := NewClient(&Config{AppID: "app", AppSecret: "secret", BaseURL: "https://test.payara.id", HTTPClient: &http.Client{Transport: mock}, SkipValidation: true})
+	_, err = skip.Transfer().CreateDisbursement(context.Background(), types.CreateDisbursementRequest{ReferenceID: "R1", Amount: 5000})
+	if errors.As(err, &ve) {
+		t.Errorf("SkipValidation: unexpected ValidationError %v", err)
+	}
+	if calls == 0 {
+		t.Error("SkipValidation: request should have been sent")
+	}
+}
@@ -0,0 +1,87 @@
+package payara
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// WaitOptions configures WaitForFinalStatus. Nil uses the defaults.
+type WaitOptions struct {
+	Interval    time.Duration // Delay before the second poll (default 2s)
+	MaxInterval time.Duration // Cap on the delay between polls (default 30s)
+	Multiplier  float64       // Growth of the delay after each poll (default 1.5; 1 polls at a fixed interval)
+	MaxWait     time.Duration // Total time to wait for a final status (default 5m)
+	// OnProgress, if set, is called after every poll with the poll number (from 1) and its result.
+	// status is nil when the poll failed with a retryable error.
+	OnProgress func(poll int, status *types.DisbursementStatusData, err error)
+}
+
+// WaitForFinalStatus polls GetDisbursementStatus for id until the status leaves PROCESS and returns the final
+// (SUCCESS or FAILED) data. Retryable lookup errors (see IsRetryable) keep polling; other errors are returned.
+// When MaxWait elapses, or ctx's deadline passes, it returns *WaitTimeoutError with the last observed status.
+// Cancelling ctx returns ctx.Err().
+func (s *transferService) WaitForFinalStatus(ctx context.Context, id string, opts *WaitOptions) (*types.DisbursementStatusData, error) {
+	interval, maxInterval, mult, maxWait := 2*time.Second, 30*time.Second, 1.5, 5*time.Minute
+	var onProgress func(int, *types.DisbursementStatusData, error)
+	if opts != nil {
+		if opts.Interval > 0 {
+			interval = opts.Interval
+		}
+		if opts.MaxInterval > 0 {
+			maxInterval = opts.MaxInterval
+		}
+		if opts.Multiplier >= 1 {
+			mult = opts.Multiplier
+		}
+		if opts.MaxWait > 0 {
+			maxWait = opts.MaxWait
+		}
+		onProgress = opts.OnProgress
+	}
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, maxWait)
+	defer cancel()
+
+	var last *types.DisbursementStatusData
+	var lastErr error
+	timeout := func() error {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return ctx.Err()
+		}
+		return &WaitTimeoutError{TransactionID: id, Waited: time.Since(start), Last: last, LastErr: lastErr}
+	}
+	for poll := 1; ; poll++ {
+		resp, err := s.GetDisbursementStatus(waitCtx, id)
+		if waitCtx.Err() != nil {
+			return nil, timeout()
+		}
+		var data *types.DisbursementStatusData
+		if err == nil {
+			data = resp.Data
+			if data == nil {
+				err = &APIError{Message: "check-status response missing data", HTTPStatus: 200}
+			}
+		}
+		if onProgress != nil {
+			onProgress(poll, data, err)
+		}
+		if err != nil {
+			if !IsRetryable(err) {
+				return nil, err
+			}
+			lastErr = err
+		} else {
+			last, lastErr = data, nil
+			if data.Status.IsFinal() {
+				return data, nil
+			}
+		}
+		if err := sleep(waitCtx, interval); err != nil {
+			return nil, timeout()
+		}
+		interval = nextBackoff(interval, maxInterval, mult)
+	}
+}
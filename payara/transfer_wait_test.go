@@ -0,0 +1,107 @@
+package payara
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/internal/paytest"
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+func statusBody(status string) string {
+	return `{"success":true,"message":"ok","data":{"transaction_id":"T1","reference_id":"R1","status":"` + status + `","amount":100000}}`
+}
+
+var fastWait = &WaitOptions{Interval: time.Millisecond, MaxInterval: 2 * time.Millisecond, MaxWait: time.Second}
+
+func TestWaitForFinalStatus_Success(t *testing.T) {
+	var polls int32
+	client := mockClient(&paytest.API{Handler: func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/api/v1/check-status/T1" {
+			t.Errorf("path %s", req.URL.Path)
+		}
+		switch atomic.AddInt32(&polls, 1) {
+		case 1:
+			return paytest.JSON(200, statusBody("PROCESS"))
+		case 2:
+			return paytest.JSON(503, `{"success":false,"message":"unavailable"}`)
+		default:
+			return paytest.JSON(200, statusBody("SUCCESS"))
+		}
+	}}, nil)
+	var progress []string
+	opts := *fastWait
+	opts.OnProgress = func(poll int, st *types.DisbursementStatusData, err error) {
+		switch {
+		case err != nil:
+			progress = append(progress, "err")
+		default:
+			progress = append(progress, string(st.Status))
+		}
+	}
+	got, err := client.Transfer().WaitForFinalStatus(context.Background(), "T1", &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != types.DisbursementStatusSuccess || got.TransactionID != "T1" {
+		t.Errorf("got %+v", got)
+	}
+	if len(progress) != 3 || progress[0] != "PROCESS" || progress[1] != "err" || progress[2] != "SUCCESS" {
+		t.Errorf("progress = %v", progress)
+	}
+}
+
+func TestWaitForFinalStatus_Timeout(t *testing.T) {
+	client := mockClient(&paytest.API{Handler: func(*http.Request) (*http.Response, error) { return paytest.JSON(200, statusBody("PROCESS")) }}, nil)
+	opts := *fastWait
+	opts.MaxWait = 30 * time.Millisecond
+	_, err := client.Transfer().WaitForFinalStatus(context.Background(), "T1", &opts)
+	var te *WaitTimeoutError
+	if !errors.As(err, &te) {
+		t.Fatalf("expected *WaitTimeoutError, got %v", err)
+	}
+	if te.Last == nil || te.Last.Status != types.DisbursementStatusProcess || te.TransactionID != "T1" {
+		t.Errorf("timeout error = %+v", te)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected errors.Is(err, context.DeadlineExceeded)")
+	}
+}
+
+func TestWaitForFinalStatus_PermanentError(t *testing.T) {
+	var polls int32
+	client := mockClient(&paytest.API{Handler: func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&polls, 1)
+		return paytest.JSON(404, `{"success":false,"message":"not found","error_code":"NOT_FOUND"}`)
+	}}, nil)
+	_, err := client.Transfer().WaitForFinalStatus(context.Background(), "T1", fastWait)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if polls != 1 {
+		t.Errorf("polls = %d, want 1", polls)
+	}
+}
+
+func TestWaitForFinalStatus_Cancelled(t *testing.T) {
+	client := mockClient(&paytest.API{Handler: func(*http.Request) (*http.Response, error) { return paytest.JSON(200, statusBody("PROCESS")) }}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := *fastWait
+	opts.OnProgress = func(int, *types.DisbursementStatusData, error) { cancel() }
+	_, err := client.Transfer().WaitForFinalStatus(ctx, "T1", &opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestDisbursementStatus_IsFinal(t *testing.T) {
+	for s, want := range map[types.DisbursementStatus]bool{"PROCESS": false, "Process": false, "": false, "SUCCESS": true, "FAILED": true} {
+		if got := s.IsFinal(); got != want {
+			t.Errorf("%q.IsFinal() = %v", s, got)
+		}
+	}
+}
@@ -2,6 +2,8 @@
 // All types follow the official documentation at https://doc.payara.id/docs/1.0/
 package types
 
+import "strings"
+
 // DisbursementStatus represents transaction status from API (check-status, disbursement response).
 // Doc: PROCESS | SUCCESS | FAILED
 type DisbursementStatus string
@@ -12,6 +14,11 @@ const (
 	DisbursementStatusFailed  DisbursementStatus = "FAILED"
 )
 
+// IsFinal reports whether s is a terminal status (anything other than PROCESS). Comparison is case-insensitive.
+func (s DisbursementStatus) IsFinal() bool {
+	return s != "" && !strings.EqualFold(string(s), string(DisbursementStatusProcess))
+}
+
 // CallbackStatus represents status in callback payload. Doc uses "Success", "Failed", "Process".
 type CallbackStatus string
 
@@ -0,0 +1,206 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// IDR is an amount in Indonesian Rupiah whole units (IDR has no minor unit in Payara).
+// It unmarshals from a JSON number (100000, 100000.0) or string ("100000", "999.793.000", "1,000,000",
+// "Rp 1.000.000", "10000.00") and marshals as a JSON number. Use Add/Sub/Mul for overflow-checked arithmetic.
+type IDR int64
+
+// ErrIDROverflow is returned by IDR arithmetic that would overflow int64.
+var ErrIDROverflow = errors.New("types: IDR arithmetic overflow")
+
+// ParseIDR parses an amount such as "100000", "999.793.000", "1,000,000", "Rp 1.000.000" or "10000.00".
+// Dots and commas are treated as thousand separators when they separate groups of three digits after a
+// leading group of 1-3 digits; a final group of any other length is a decimal fraction, which must be zero.
+// Input such as "10000.000" or "12345.678" fits neither reading and is rejected.
+func ParseIDR(s string) (IDR, error) {
+	orig := s
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = strings.TrimSpace(s[1:])
+	}
+	for _, prefix := range []string{"Rp.", "Rp", "IDR"} {
+		if strings.HasPrefix(s, prefix) {
+			s = strings.TrimSpace(s[len(prefix):])
+			break
+		}
+	}
+	if !neg && strings.HasPrefix(s, "-") {
+		neg, s = true, strings.TrimSpace(s[1:])
+	}
+	if s == "" {
+		return 0, fmt.Errorf("types: invalid IDR amount %q", orig)
+	}
+	groups := strings.FieldsFunc(s, func(r rune) bool { return r == '.' || r == ',' })
+	if len(groups) == 0 || strings.HasSuffix(s, ".") || strings.HasSuffix(s, ",") {
+		return 0, fmt.Errorf("types: invalid IDR amount %q", orig)
+	}
+	if len(groups) > 1 && len(groups[len(groups)-1]) != 3 {
+		frac := groups[len(groups)-1]
+		if strings.Trim(frac, "0") != "" || !isDigits(frac) {
+			return 0, fmt.Errorf("types: IDR amount %q has a non-zero fraction", orig)
+		}
+		groups = groups[:len(groups)-1]
+	}
+	for i, g := range groups {
+		// With thousand separators the leading group has 1-3 digits; "10000.000" is a decimal, not 10 million.
+		if !isDigits(g) || (i > 0 && len(g) != 3) || (i == 0 && len(groups) > 1 && len(g) > 3) {
+			return 0, fmt.Errorf("types: invalid IDR amount %q", orig)
+		}
+	}
+	n, err := strconv.ParseInt(strings.Join(groups, ""), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("types: invalid IDR amount %q: %w", orig, err)
+	}
+	if neg {
+		n = -n
+	}
+	return IDR(n), nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *IDR) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if strings.TrimSpace(s) == "" {
+			*a = 0
+			return nil
+		}
+		v, err := ParseIDR(s)
+		if err != nil {
+			return err
+		}
+		*a = v
+		return nil
+	}
+	if n, err := strconv.ParseInt(string(data), 10, 64); err == nil {
+		*a = IDR(n)
+		return nil
+	}
+	f, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("types: invalid IDR amount %s", data)
+	}
+	if f != math.Trunc(f) {
+		return fmt.Errorf("types: IDR amount %s is not a whole number", data)
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, so compare against 2^63 exactly.
+	if f >= 1<<63 || f < -(1<<63) {
+		return fmt.Errorf("types: IDR amount %s out of range: %w", data, ErrIDROverflow)
+	}
+	*a = IDR(f)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. Amounts are sent as JSON numbers.
+func (a IDR) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(a), 10)), nil
+}
+
+// Int64 returns the amount as int64.
+func (a IDR) Int64() int64 { return int64(a) }
+
+// Add returns a+b, or ErrIDROverflow.
+func (a IDR) Add(b IDR) (IDR, error) {
+	s := a + b
+	if (b > 0 && s < a) || (b < 0 && s > a) {
+		return 0, ErrIDROverflow
+	}
+	return s, nil
+}
+
+// Sub returns a-b, or ErrIDROverflow.
+func (a IDR) Sub(b IDR) (IDR, error) {
+	s := a - b
+	if (b > 0 && s > a) || (b < 0 && s < a) {
+		return 0, ErrIDROverflow
+	}
+	return s, nil
+}
+
+// Mul returns a*n, or ErrIDROverflow.
+func (a IDR) Mul(n int64) (IDR, error) {
+	if a == 0 || n == 0 {
+		return 0, nil
+	}
+	p := int64(a) * n
+	if p/n != int64(a) || (int64(a) == -1 && n == math.MinInt64) || (n == -1 && int64(a) == math.MinInt64) {
+		return 0, ErrIDROverflow
+	}
+	return IDR(p), nil
+}
+
+// SumIDR adds amounts, or returns ErrIDROverflow.
+func SumIDR(amounts ...IDR) (IDR, error) {
+	var total IDR
+	for _, a := range amounts {
+		var err error
+		if total, err = total.Add(a); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// String formats the amount in Indonesian locale, e.g. "Rp 1.000.000" or "-Rp 2.500".
+func (a IDR) String() string {
+	return formatIDR(a, "Rp ")
+}
+
+// Format formats the amount with dot thousand separators and no currency prefix, e.g. "1.000.000".
+func (a IDR) Format() string {
+	return formatIDR(a, "")
+}
+
+func formatIDR(a IDR, prefix string) string {
+	var digits string
+	neg := a < 0
+	if a == math.MinInt64 {
+		digits = "9223372036854775808"
+	} else {
+		n := int64(a)
+		if neg {
+			n = -n
+		}
+		digits = strconv.FormatInt(n, 10)
+	}
+	var b strings.Builder
+	if neg {
+		b.WriteByte('-')
+	}
+	b.WriteString(prefix)
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,129 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseIDR(t *testing.T) {
+	tests := []struct {
+		in   string
+		want IDR
+		ok   bool
+	}{
+		{"100000", 100000, true},
+		{"999.793.000", 999793000, true},
+		{"1,000,000", 1000000, true},
+		{"Rp 1.000.000", 1000000, true},
+		{"Rp. 2.500", 2500, true},
+		{"IDR 10,000", 10000, true},
+		{"10000.00", 10000, true},
+		{"1.000.000,00", 1000000, true},
+		{"-Rp 2.500", -2500, true},
+		{"10.000", 10000, true},
+		{"1.5", 0, false},
+		{"1.000,50", 0, false},
+		{"1.00.000", 0, false},
+		{"abc", 0, false},
+		{"", 0, false},
+		{"1.000.", 0, false},
+		{"10000.000", 0, false},
+		{"12345.678", 0, false},
+		{"1000,000", 0, false},
+		{"100.000.000", 100000000, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseIDR(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseIDR(%q) = %d, %v; want %d, ok=%v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestIDR_JSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want IDR
+	}{
+		{`100000`, 100000},
+		{`100000.0`, 100000},
+		{`"999.793.000"`, 999793000},
+		{`"10000"`, 10000},
+		{`""`, 0},
+	}
+	for _, tt := range tests {
+		var a IDR
+		if err := json.Unmarshal([]byte(tt.in), &a); err != nil || a != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, %v; want %d", tt.in, a, err, tt.want)
+		}
+	}
+	var a IDR
+	if err := json.Unmarshal([]byte(`100.5`), &a); err == nil {
+		t.Error("expected error for fractional amount")
+	}
+	for _, in := range []string{`9223372036854775807.0`, `1e19`, `-1e19`} {
+		if err := json.Unmarshal([]byte(in), &a); !errors.Is(err, ErrIDROverflow) {
+			t.Errorf("Unmarshal(%s) = %d, %v; want ErrIDROverflow", in, a, err)
+		}
+	}
+	if err := json.Unmarshal([]byte(`-9223372036854775808.0`), &a); err != nil || a != math.MinInt64 {
+		t.Errorf("Unmarshal(min) = %d, %v", a, err)
+	}
+	b, err := json.Marshal(struct {
+		Amount IDR `json:"amount"`
+	}{Amount: 150000})
+	if err != nil || string(b) != `{"amount":150000}` {
+		t.Errorf("Marshal: %s, %v", b, err)
+	}
+}
+
+func TestIDR_Arithmetic(t *testing.T) {
+	if s, err := IDR(100000).Add(2500); err != nil || s != 102500 {
+		t.Errorf("Add: %d, %v", s, err)
+	}
+	if _, err := IDR(math.MaxInt64).Add(1); !errors.Is(err, ErrIDROverflow) {
+		t.Errorf("Add overflow: %v", err)
+	}
+	if s, err := IDR(100).Sub(250); err != nil || s != -150 {
+		t.Errorf("Sub: %d, %v", s, err)
+	}
+	if _, err := IDR(math.MinInt64).Sub(1); !errors.Is(err, ErrIDROverflow) {
+		t.Errorf("Sub overflow: %v", err)
+	}
+	if p, err := IDR(2500).Mul(4); err != nil || p != 10000 {
+		t.Errorf("Mul: %d, %v", p, err)
+	}
+	if _, err := IDR(math.MaxInt64 / 2).Mul(3); !errors.Is(err, ErrIDROverflow) {
+		t.Errorf("Mul overflow: %v", err)
+	}
+	if _, err := IDR(math.MinInt64).Mul(-1); !errors.Is(err, ErrIDROverflow) {
+		t.Errorf("Mul MinInt64*-1: %v", err)
+	}
+	if total, err := SumIDR(10000, 20000, 2500); err != nil || total != 32500 {
+		t.Errorf("SumIDR: %d, %v", total, err)
+	}
+}
+
+func TestIDR_String(t *testing.T) {
+	tests := []struct {
+		in   IDR
+		want string
+	}{
+		{0, "Rp 0"},
+		{999, "Rp 999"},
+		{1000, "Rp 1.000"},
+		{1000000, "Rp 1.000.000"},
+		{-2500, "-Rp 2.500"},
+		{math.MinInt64, "-Rp 9.223.372.036.854.775.808"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("String(%d) = %q, want %q", int64(tt.in), got, tt.want)
+		}
+	}
+	if got := IDR(999793000).Format(); got != "999.793.000" {
+		t.Errorf("Format: %q", got)
+	}
+}
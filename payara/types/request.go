@@ -8,11 +8,11 @@ type LoginRequest struct {
 }
 
 // CreateDisbursementRequest is the body for POST /api/v1/disbursement.
-// Amount: min IDR 10,000, max IDR 50,000,000. Use int64 for IDR whole units (no decimal).
+// Amount: min IDR 10,000, max IDR 50,000,000. IDR whole units (no decimal).
 // Doc: reference_id must be unique; duplicate rejected.
 type CreateDisbursementRequest struct {
 	ReferenceID   string `json:"reference_id"`   // Unique transaction reference ID
-	Amount        int64  `json:"amount"`        // Disbursement amount in IDR (whole units)
+	Amount        IDR    `json:"amount"`        // Disbursement amount in IDR (whole units)
 	BankCode      string `json:"bank_code"`      // Recipient bank code
 	AccountNumber string `json:"account_number"` // Recipient account number
 	AccountName   string `json:"account_name"`   // Recipient account name
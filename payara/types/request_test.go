@@ -2,6 +2,9 @@ package types
 
 import (
 	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -56,3 +59,74 @@ func TestCheckAccountRequest_JSON(t *testing.T) {
 		t.Errorf("round-trip: got %+v", out)
 	}
 }
+
+func TestCreateDisbursementRequest_Validate_ok(t *testing.T) {
+	reqs := []CreateDisbursementRequest{
+		{ReferenceID: "REF-1", Amount: 10000, BankCode: "5", AccountNumber: "12330922231", AccountName: "Asep"},
+		{ReferenceID: "REF-2", Amount: 50000000, BankCode: "282", AccountNumber: "081212239133", AccountName: "Zen", Description: "Payout"},
+		// Length limits Payara does not document are left to the API.
+		{ReferenceID: strings.Repeat("R", 100), Amount: 10000, BankCode: "5", AccountNumber: "12330922231", AccountName: strings.Repeat("A", 150)},
+	}
+	for _, r := range reqs {
+		if err := r.Validate(); err != nil {
+			t.Errorf("%.10s: unexpected error %v", r.ReferenceID, err)
+		}
+	}
+}
+
+func TestCreateDisbursementRequest_Validate_listsEveryProblem(t *testing.T) {
+	req := CreateDisbursementRequest{
+		ReferenceID:   "",
+		Amount:        9999,
+		BankCode:      "BCA",
+		AccountNumber: "12-34",
+		AccountName:   " ",
+		Description:   strings.Repeat("x", 256),
+	}
+	err := req.Validate()
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	for _, f := range []string{"reference_id", "amount", "bank_code", "account_number", "account_name", "description"} {
+		if len(ve.Field(f)) != 1 {
+			t.Errorf("field %s: got %v", f, ve.Field(f))
+		}
+	}
+}
+
+func TestCreateDisbursementRequest_Validate_bankCodeAndDescription(t *testing.T) {
+	req := CreateDisbursementRequest{ReferenceID: "R", Amount: 10000, BankCode: "1234567", AccountNumber: "12330922231", AccountName: "A"}
+	if err := req.Validate(); err != nil {
+		t.Errorf("long numeric bank_code must be left to Payara or the registry: %v", err)
+	}
+	req.Description = strings.Repeat("x", MaxDescriptionLength+1)
+	var ve *ValidationError
+	if err := req.Validate(); !errors.As(err, &ve) || len(ve.Field("description")) != 1 ||
+		!strings.Contains(ve.Field("description")[0].Message, strconv.Itoa(MaxDescriptionLength)) {
+		t.Errorf("description: got %v", err)
+	}
+}
+
+func TestCreateDisbursementRequest_Validate_accountNumberRules(t *testing.T) {
+	tests := []struct {
+		bankCode, account string
+		ok                bool
+	}{
+		{"5", "1234", false},
+		{"5", "123456789012345678901", false},
+		{"281", "12330922231", false},
+		{"281", "0812", false},
+		{"281", "6281212239281", true},
+		{"4", "12340995811", true},
+	}
+	for _, tt := range tests {
+		req := CreateDisbursementRequest{ReferenceID: "R", Amount: 10000, BankCode: tt.bankCode, AccountNumber: tt.account, AccountName: "A"}
+		if err := req.Validate(); (err == nil) != tt.ok {
+			t.Errorf("bank %s account %s: got %v, want ok=%v", tt.bankCode, tt.account, err, tt.ok)
+		}
+	}
+	if err := (CreateDisbursementRequest{ReferenceID: "R", Amount: 50000001, BankCode: "5", AccountNumber: "12345", AccountName: "A"}).Validate(); err == nil {
+		t.Error("expected max amount error")
+	}
+}
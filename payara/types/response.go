@@ -3,12 +3,11 @@ package types
 import (
 	"encoding/json"
 	"strconv"
-	"strings"
 )
 
 // Meta is common response meta. Doc: timestamp, version (optional)
 type Meta struct {
-	Timestamp string  `json:"timestamp,omitempty"`
+	Timestamp *Timestamp `json:"timestamp,omitempty"` // Nil when absent
 	Version   string  `json:"version,omitempty"`
 	RetryAfter *int   `json:"retry_after,omitempty"` // For 429 rate limit
 }
@@ -47,7 +46,16 @@ func (f *FlexString) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Envelope is the common response envelope: success, message, data, meta. T is the data object type.
+type Envelope[T any] struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Data    *T     `json:"data,omitempty"`
+	Meta    *Meta  `json:"meta,omitempty"`
+}
+
 // GenericAPIResponse is the common envelope: success, message, data, meta.
+// Deprecated: use Envelope[T] with a concrete data type, or Envelope[json.RawMessage] for undecoded data.
 type GenericAPIResponse struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
@@ -56,12 +64,7 @@ type GenericAPIResponse struct {
 }
 
 // LoginResponse is the full login response.
-type LoginResponse struct {
-	Success bool               `json:"success"`
-	Message string             `json:"message"`
-	Data    *LoginResponseData `json:"data,omitempty"`
-	Meta    *Meta              `json:"meta,omitempty"`
-}
+type LoginResponse = Envelope[LoginResponseData]
 
 // ErrorResponse is the documented error format. Doc: success=false, message, error_code
 type ErrorResponse struct {
@@ -77,25 +80,21 @@ type ErrorResponse struct {
 type CreateDisbursementResponseData struct {
 	TransactionID string             `json:"transaction_id"`
 	ReferenceID   string             `json:"reference_id"`
-	Amount        int64              `json:"amount"`
-	Fee           int64              `json:"fee"`
-	TotalAmount   int64              `json:"total_amount"`
+	Amount        IDR                `json:"amount"`
+	Fee           IDR                `json:"fee"`
+	TotalAmount   IDR                `json:"total_amount"`
 	Status        DisbursementStatus `json:"status"`
 	BankCode      string             `json:"bank_code"`
 	BankName      string             `json:"bank_name"`
 	AccountNumber string             `json:"account_number"`
 	AccountName   string             `json:"account_name"`
 	Description   string             `json:"description,omitempty"`
-	CreatedAt     string             `json:"created_at"`
+	CreatedAt     Timestamp          `json:"created_at"`
+	FailureReason *string            `json:"failure_reason,omitempty"` // If FAILED, when Payara reports it
 }
 
 // CreateDisbursementResponse is the full response for POST /api/v1/disbursement.
-type CreateDisbursementResponse struct {
-	Success bool                            `json:"success"`
-	Message string                          `json:"message"`
-	Data    *CreateDisbursementResponseData `json:"data,omitempty"`
-	Meta    *Meta                           `json:"meta,omitempty"`
-}
+type CreateDisbursementResponse = Envelope[CreateDisbursementResponseData]
 
 // DisbursementStatusData is the data object from check-status.
 // Doc: transaction_id, reference_id, status, amount, fee, total_amount, bank_code, bank_name,
@@ -104,74 +103,51 @@ type DisbursementStatusData struct {
 	TransactionID  string             `json:"transaction_id"`
 	ReferenceID    string             `json:"reference_id"`
 	Status         DisbursementStatus `json:"status"`
-	Amount         int64             `json:"amount"`
-	Fee            int64             `json:"fee"`
-	TotalAmount    int64             `json:"total_amount"`
+	Amount         IDR               `json:"amount"`
+	Fee            IDR               `json:"fee"`
+	TotalAmount    IDR               `json:"total_amount"`
 	BankCode       string            `json:"bank_code"`
 	BankName       string            `json:"bank_name"`
 	AccountNumber  string            `json:"account_number"`
 	AccountName    string            `json:"account_name"`
 	Description    string            `json:"description,omitempty"`
-	CreatedAt      string            `json:"created_at"`
-	ProcessedAt    string            `json:"processed_at,omitempty"`
+	CreatedAt      Timestamp         `json:"created_at"`
+	ProcessedAt    *Timestamp        `json:"processed_at,omitempty"` // Nil until processed
 	FailureReason  *string           `json:"failure_reason,omitempty"`
 }
 
 // DisbursementStatusResponse is the full response for GET /api/v1/check-status
-type DisbursementStatusResponse struct {
-	Success bool                     `json:"success"`
-	Message string                   `json:"message"`
-	Data    *DisbursementStatusData  `json:"data,omitempty"`
-	Meta    *Meta                    `json:"meta,omitempty"`
+type DisbursementStatusResponse = Envelope[DisbursementStatusData]
+
+// CheckAccountResponseData is the data object from POST /api/v1/check-account.
+// Doc: bank_code, bank_name, account_number, account_name (resolved beneficiary name), is_valid
+type CheckAccountResponseData struct {
+	BankCode      string `json:"bank_code"`
+	BankName      string `json:"bank_name"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"` // Name registered at the bank / e-wallet
+	IsValid       bool   `json:"is_valid"`
 }
 
+// CheckAccountResponse is the full response for POST /api/v1/check-account
+type CheckAccountResponse = Envelope[CheckAccountResponseData]
+
 // BalanceData is the data object from GET /api/v1/balance.
 // API may return merchant_id as number or string, and balance as number or string with thousand separators (e.g. "999.793.000").
 type BalanceData struct {
 	MerchantID  FlexString    `json:"merchant_id"`
-	Balance     BalanceAmount `json:"balance"` // IDR whole units (API may return "999.793.000")
+	Balance     IDR           `json:"balance"` // IDR whole units (API may return "999.793.000")
 	Currency    string        `json:"currency"`
-	LastUpdated string        `json:"last_updated"`
+	LastUpdated Timestamp     `json:"last_updated"`
 	Status      AccountStatus `json:"status"`
 }
 
-// BalanceAmount is balance in IDR whole units. Unmarshals from JSON number or string with optional thousand separators (e.g. "999.793.000").
-type BalanceAmount int64
-
-// UnmarshalJSON implements json.Unmarshaler.
-func (b *BalanceAmount) UnmarshalJSON(data []byte) error {
-	if len(data) == 0 {
-		return nil
-	}
-	if data[0] == '"' {
-		var s string
-		if err := json.Unmarshal(data, &s); err != nil {
-			return err
-		}
-		s = strings.ReplaceAll(s, ".", "")
-		s = strings.ReplaceAll(s, ",", "")
-		n, err := strconv.ParseInt(s, 10, 64)
-		if err != nil {
-			return err
-		}
-		*b = BalanceAmount(n)
-		return nil
-	}
-	var n int64
-	if err := json.Unmarshal(data, &n); err != nil {
-		return err
-	}
-	*b = BalanceAmount(n)
-	return nil
-}
+// BalanceAmount is balance in IDR whole units.
+// Deprecated: use IDR, which accepts the same number and string formats.
+type BalanceAmount = IDR
 
 // BalanceResponse is the full response for GET /api/v1/balance
-type BalanceResponse struct {
-	Success bool         `json:"success"`
-	Message string       `json:"message"`
-	Data    *BalanceData `json:"data,omitempty"`
-	Meta    *Meta        `json:"meta,omitempty"`
-}
+type BalanceResponse = Envelope[BalanceData]
 
 // DisbursementListResponse is the response for list disbursement.
 // TODO: Payara 1.0 docs do not document list endpoint; structure may change when API is available.
@@ -184,12 +160,32 @@ type DisbursementListResponse struct {
 
 // CallbackPayload is the POST body sent by Payara to the configured callback URL.
 // Doc: transaction_id, amount (string IDR), status (Success|Failed|Process), reference_id, admin_fee (string), is_refund (bool)
-// No signature verification documented; add if Payara provides it later.
+// No signature verification documented; see payara/webhook verifiers (IP allowlist, shared secret/HMAC, lookup).
 type CallbackPayload struct {
 	TransactionID string         `json:"transaction_id"`
-	Amount        string         `json:"amount"`   // String in IDR
+	Amount        IDR            `json:"amount"`   // Sent as string in IDR
 	Status        CallbackStatus `json:"status"`
 	ReferenceID   string         `json:"reference_id"`
-	AdminFee      string         `json:"admin_fee"` // String
+	AdminFee      IDR            `json:"admin_fee"` // Sent as string
 	IsRefund      bool           `json:"is_refund"` // true = failed refund, false = regular failed
 }
+
+// MarshalJSON implements json.Marshaler, writing amount and admin_fee as strings like Payara does.
+func (p CallbackPayload) MarshalJSON() ([]byte, error) {
+	type wire struct {
+		TransactionID string         `json:"transaction_id"`
+		Amount        string         `json:"amount"`
+		Status        CallbackStatus `json:"status"`
+		ReferenceID   string         `json:"reference_id"`
+		AdminFee      string         `json:"admin_fee"`
+		IsRefund      bool           `json:"is_refund"`
+	}
+	return json.Marshal(wire{
+		TransactionID: p.TransactionID,
+		Amount:        strconv.FormatInt(int64(p.Amount), 10),
+		Status:        p.Status,
+		ReferenceID:   p.ReferenceID,
+		AdminFee:      strconv.FormatInt(int64(p.AdminFee), 10),
+		IsRefund:      p.IsRefund,
+	})
+}
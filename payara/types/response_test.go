@@ -124,7 +124,46 @@ func TestCallbackPayload_Unmarshal(t *testing.T) {
 	if out.TransactionID != "100028355123792503" || out.ReferenceID != "REFID153966210" || out.Status != CallbackStatusSuccess {
 		t.Errorf("got %+v", out)
 	}
-	if out.Amount != "10000" || out.AdminFee != "3500" || out.IsRefund != false {
-		t.Errorf("Amount=%q AdminFee=%q IsRefund=%v", out.Amount, out.AdminFee, out.IsRefund)
+	if out.Amount != 10000 || out.AdminFee != 3500 || out.IsRefund != false {
+		t.Errorf("Amount=%d AdminFee=%d IsRefund=%v", out.Amount, out.AdminFee, out.IsRefund)
+	}
+}
+
+func TestCallbackPayload_MarshalJSON_stringAmounts(t *testing.T) {
+	b, err := json.Marshal(CallbackPayload{TransactionID: "T1", Amount: 10000, Status: CallbackStatusSuccess, ReferenceID: "R1", AdminFee: 3500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"transaction_id":"T1","amount":"10000","status":"Success","reference_id":"R1","admin_fee":"3500","is_refund":false}`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
+
+func TestEnvelope_Unmarshal(t *testing.T) {
+	type custom struct {
+		Name string `json:"name"`
+	}
+	var out Envelope[custom]
+	if err := json.Unmarshal([]byte(`{"success":true,"message":"ok","data":{"name":"x"},"meta":{"timestamp":"2024-01-01T00:00:00Z"}}`), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Success || out.Data == nil || out.Data.Name != "x" || out.Meta == nil {
+		t.Errorf("got %+v", out)
+	}
+	var raw Envelope[json.RawMessage]
+	if err := json.Unmarshal([]byte(`{"success":true,"message":"ok","data":[1,2]}`), &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw.Data == nil || string(*raw.Data) != "[1,2]" {
+		t.Errorf("raw data: %v", raw.Data)
+	}
+	// The deprecated GenericAPIResponse keeps its interface{} Data.
+	var generic GenericAPIResponse
+	if err := json.Unmarshal([]byte(`{"success":true,"message":"ok","data":{"a":1}}`), &generic); err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := generic.Data.(map[string]interface{}); !ok || m["a"] != float64(1) {
+		t.Errorf("generic data: %#v", generic.Data)
 	}
 }
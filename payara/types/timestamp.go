@@ -0,0 +1,134 @@
+package types
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Jakarta is the Asia/Jakarta zone (WIB, UTC+7) used for Payara timestamps without an offset.
+// If the tz database is unavailable it is a fixed UTC+7 zone, which matches since WIB has no DST.
+var Jakarta = loadJakarta()
+
+func loadJakarta() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Jakarta"); err == nil {
+		return loc
+	}
+	return time.FixedZone("WIB", 7*60*60)
+}
+
+// localLayouts are the offset-less formats Payara returns; they are interpreted in Jakarta time.
+var localLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// Timestamp is a time from a Payara response. It accepts RFC3339, "2006-01-02 15:04:05" (WIB, Asia/Jakarta)
+// and Unix epoch seconds or milliseconds, as JSON strings or numbers. Raw keeps the value as received and is
+// what MarshalJSON writes back, so payloads round-trip unchanged.
+//
+// An unrecognised value does not fail decoding: Time stays zero and Raw holds the value.
+// Time is a named field rather than embedded so time.Time's text and binary marshalers, which would ignore
+// Raw, are not promoted.
+type Timestamp struct {
+	Time time.Time
+	Raw  string
+
+	rawNumber bool // Raw came from a JSON number
+}
+
+// NewTimestamp returns a Timestamp for t with no raw value.
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t}
+}
+
+// ParseTimestamp parses s in any of the formats Payara returns. An empty string gives the zero Timestamp.
+func ParseTimestamp(s string) (Timestamp, error) {
+	ts := Timestamp{Raw: s}
+	v := strings.TrimSpace(s)
+	if v == "" {
+		return ts, nil
+	}
+	if t, ok := parseEpoch(v); ok {
+		ts.Time = t
+		return ts, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		ts.Time = t
+		return ts, nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, v, Jakarta); err == nil {
+			ts.Time = t
+			return ts, nil
+		}
+	}
+	return ts, &time.ParseError{Layout: time.RFC3339, Value: s, Message: ": unrecognised Payara timestamp"}
+}
+
+// parseEpoch parses Unix seconds (optionally fractional) or, for values of 13+ integer digits, milliseconds.
+func parseEpoch(s string) (time.Time, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) || f < 0 || strings.ContainsAny(s, "eE+-") {
+		return time.Time{}, false
+	}
+	if f >= 1e12 {
+		return time.UnixMilli(int64(f)).In(Jakarta), true
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)).In(Jakarta), true
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 || string(data) == "null" {
+		*t = Timestamp{}
+		return nil
+	}
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*t, _ = ParseTimestamp(s)
+		return nil
+	}
+	*t, _ = ParseTimestamp(string(data))
+	t.rawNumber = true
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. It writes Raw when set (as a number if it was received as one),
+// otherwise RFC3339, or null for the zero Timestamp.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	switch {
+	case t.Raw != "" && t.rawNumber:
+		return []byte(t.Raw), nil
+	case t.Raw != "":
+		return json.Marshal(t.Raw)
+	case t.Time.IsZero():
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Time.Format(time.RFC3339Nano))
+}
+
+// String returns Raw when set, otherwise the time in RFC3339.
+func (t Timestamp) String() string {
+	if t.Raw != "" {
+		return t.Raw
+	}
+	if t.Time.IsZero() {
+		return ""
+	}
+	return t.Time.Format(time.RFC3339)
+}
+
+// Valid reports whether a time was parsed (false for empty or unrecognised values).
+func (t Timestamp) Valid() bool {
+	return !t.Time.IsZero()
+}
@@ -0,0 +1,119 @@
+package types
+
+import (
+	"encoding"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	wib := time.Date(2024, 1, 15, 10, 30, 0, 0, Jakarta)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2024-01-15T10:30:00+07:00", wib},
+		{"2024-01-15T03:30:00Z", wib},
+		{"2024-01-15T03:30:00.000000Z", wib},
+		{"2024-01-15 10:30:00", wib}, // WIB, not UTC
+		{"2024-01-15T10:30:00", wib},
+		{"2024-01-15 10:30:00.000000", wib},
+		{"1705289400", wib},
+		{"1705289400000", wib},
+		{"1705289400.5", wib.Add(500 * time.Millisecond)},
+		{"2024-01-15", time.Date(2024, 1, 15, 0, 0, 0, 0, Jakarta)},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimestamp(tt.in)
+		if err != nil {
+			t.Errorf("%q: %v", tt.in, err)
+			continue
+		}
+		if !got.Time.Equal(tt.want) || got.Raw != tt.in {
+			t.Errorf("%q: got %v (raw %q), want %v", tt.in, got.Time, got.Raw, tt.want)
+		}
+	}
+	if ts, err := ParseTimestamp(""); err != nil || ts.Valid() {
+		t.Errorf("empty: %+v %v", ts, err)
+	}
+	if ts, err := ParseTimestamp("yesterday"); err == nil || ts.Valid() || ts.Raw != "yesterday" {
+		t.Errorf("invalid: %+v %v", ts, err)
+	}
+}
+
+func TestTimestamp_JSONRoundTrip(t *testing.T) {
+	in := `{"created_at":"2024-01-15 10:30:00","processed_at":1705289400,"status":"SUCCESS"}`
+	var d DisbursementStatusData
+	if err := json.Unmarshal([]byte(in), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.ProcessedAt == nil || !d.CreatedAt.Time.Equal(d.ProcessedAt.Time) {
+		t.Errorf("created %v, processed %v", d.CreatedAt.Time, d.ProcessedAt.Time)
+	}
+	if d.CreatedAt.Time.UTC().Hour() != 3 {
+		t.Errorf("created_at should be WIB: %v", d.CreatedAt.Time.UTC())
+	}
+	out, err := json.Marshal(struct {
+		CreatedAt   Timestamp `json:"created_at"`
+		ProcessedAt Timestamp `json:"processed_at"`
+	}{d.CreatedAt, *d.ProcessedAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"created_at":"2024-01-15 10:30:00","processed_at":1705289400}`; string(out) != want {
+		t.Errorf("round trip = %s, want %s", out, want)
+	}
+}
+
+func TestTimestamp_LenientDecode(t *testing.T) {
+	var m Meta
+	if err := json.Unmarshal([]byte(`{"timestamp":"not a time","version":"1.0"}`), &m); err != nil {
+		t.Fatalf("unrecognised timestamps must not fail decoding: %v", err)
+	}
+	if m.Timestamp == nil || m.Timestamp.Valid() || m.Timestamp.Raw != "not a time" || m.Version != "1.0" {
+		t.Errorf("got %+v", m)
+	}
+	var null Timestamp
+	if err := json.Unmarshal([]byte(`null`), &null); err != nil || null.Valid() {
+		t.Errorf("null: %+v %v", null, err)
+	}
+}
+
+func TestTimestamp_MarshalWithoutRaw(t *testing.T) {
+	ts := NewTimestamp(time.Date(2024, 1, 15, 10, 30, 0, 0, Jakarta))
+	out, _ := json.Marshal(ts)
+	if string(out) != `"2024-01-15T10:30:00+07:00"` {
+		t.Errorf("got %s", out)
+	}
+	if ts.String() != "2024-01-15T10:30:00+07:00" {
+		t.Errorf("String = %s", ts.String())
+	}
+	out, _ = json.Marshal(Timestamp{})
+	if string(out) != "null" {
+		t.Errorf("zero = %s", out)
+	}
+}
+
+func TestTimestamp_OptionalFieldsOmitted(t *testing.T) {
+	out, err := json.Marshal(Meta{Version: "1.0"})
+	if err != nil || string(out) != `{"version":"1.0"}` {
+		t.Errorf("meta = %s, %v", out, err)
+	}
+	out, err = json.Marshal(DisbursementStatusData{TransactionID: "T1"})
+	if err != nil || strings.Contains(string(out), "processed_at") {
+		t.Errorf("processed_at must be omitted when unset: %s, %v", out, err)
+	}
+}
+
+func TestTimestamp_TextMarshalKeepsRaw(t *testing.T) {
+	ts, _ := ParseTimestamp("2024-01-15 10:30:00")
+	if _, ok := interface{}(ts).(encoding.TextMarshaler); ok {
+		t.Error("Timestamp must not expose time.Time's MarshalText, which drops Raw")
+	}
+	out, _ := json.Marshal(map[string]Timestamp{"at": ts})
+	if string(out) != `{"at":"2024-01-15 10:30:00"}` {
+		t.Errorf("got %s", out)
+	}
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Disbursement limits. The amount range is documented by Payara; MaxDescriptionLength is the SDK's
+// client-side cap for the optional description.
+const (
+	MinDisbursementAmount IDR = 10_000
+	MaxDisbursementAmount IDR = 50_000_000
+	MaxDescriptionLength      = 255 // Characters
+)
+
+// EWalletBankCodes are bank_code values for e-wallets, whose account_number is a mobile number.
+// Doc: Sandbox Data Dummy (OVO 281, DANA 282, GOPAY 283). Validate uses them unless a DestinationChecker is given.
+var EWalletBankCodes = map[string]bool{
+	"281": true,
+	"282": true,
+	"283": true,
+}
+
+// DestinationChecker checks bank_code and account_number against the destinations a merchant supports
+// (e.g. *banks.Registry, which can be refreshed at runtime). It returns the problems found, if any.
+type DestinationChecker interface {
+	CheckDestination(bankCode, accountNumber string) []FieldError
+}
+
+// FieldError is a single field problem found by Validate.
+type FieldError struct {
+	Field   string // JSON field name, e.g. "amount"
+	Message string
+}
+
+// ValidationError lists every field problem in a request. It is returned before any HTTP call is made.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Field returns the problems reported for the given JSON field name.
+func (e *ValidationError) Field(name string) []FieldError {
+	var out []FieldError
+	for _, fe := range e.Errors {
+		if fe.Field == name {
+			out = append(out, fe)
+		}
+	}
+	return out
+}
+
+func (e *ValidationError) add(field, msg string) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: msg})
+}
+
+// Validate checks the documented rules: non-empty reference_id and amount within
+// [MinDisbursementAmount, MaxDisbursementAmount]; plus numeric bank_code, account_number format
+// (mobile number for e-wallets, 5–20 digits for banks), non-empty account_name and description length.
+// Uniqueness of reference_id can only be enforced by Payara. Returns *ValidationError or nil.
+func (r CreateDisbursementRequest) Validate() error {
+	return r.ValidateWith(nil)
+}
+
+// ValidateWith is Validate with the destination rules taken from dc: once bank_code is well-formed, dc decides
+// whether it is supported and which account_number format applies. A nil dc uses the built-in rules.
+func (r CreateDisbursementRequest) ValidateWith(dc DestinationChecker) error {
+	ve := &ValidationError{}
+	if strings.TrimSpace(r.ReferenceID) == "" {
+		ve.add("reference_id", "is required")
+	}
+	if r.Amount < MinDisbursementAmount {
+		ve.add("amount", "must be at least "+MinDisbursementAmount.String())
+	} else if r.Amount > MaxDisbursementAmount {
+		ve.add("amount", "must be at most "+MaxDisbursementAmount.String())
+	}
+	bankCodeOK := false
+	if r.BankCode == "" {
+		ve.add("bank_code", "is required")
+	} else if !isDigits(r.BankCode) {
+		ve.add("bank_code", "must contain digits only")
+	} else {
+		bankCodeOK = true
+	}
+	if dc != nil && bankCodeOK {
+		ve.Errors = append(ve.Errors, dc.CheckDestination(r.BankCode, r.AccountNumber)...)
+	} else if msg := accountNumberProblem(r.BankCode, r.AccountNumber); msg != "" {
+		ve.add("account_number", msg)
+	}
+	if strings.TrimSpace(r.AccountName) == "" {
+		ve.add("account_name", "is required")
+	}
+	if utf8.RuneCountInString(r.Description) > MaxDescriptionLength {
+		ve.add("description", "must be at most "+strconv.Itoa(MaxDescriptionLength)+" characters")
+	}
+	if len(ve.Errors) > 0 {
+		return ve
+	}
+	return nil
+}
+
+// accountNumberProblem returns a message if accountNumber is not valid for bankCode, else "".
+func accountNumberProblem(bankCode, accountNumber string) string {
+	if accountNumber == "" {
+		return "is required"
+	}
+	if !isDigits(accountNumber) {
+		return "must contain digits only"
+	}
+	if EWalletBankCodes[bankCode] {
+		if !strings.HasPrefix(accountNumber, "08") && !strings.HasPrefix(accountNumber, "628") {
+			return "must be a mobile number starting with 08 or 628 for e-wallets"
+		}
+		if len(accountNumber) < 10 || len(accountNumber) > 14 {
+			return "must be 10-14 digits for e-wallets"
+		}
+		return ""
+	}
+	if len(accountNumber) < 5 || len(accountNumber) > 20 {
+		return "must be 5-20 digits for banks"
+	}
+	return ""
+}
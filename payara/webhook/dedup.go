@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// DedupStore remembers which callbacks have been handed to business logic so Payara's retry
+// deliveries are processed only once. Implementations must be safe for concurrent use.
+type DedupStore interface {
+	// Claim atomically records key. It returns false if key was already claimed (duplicate delivery).
+	Claim(ctx context.Context, key string) (bool, error)
+	// Release forgets key so a later delivery is processed again. Handler calls it when a callback fails.
+	Release(ctx context.Context, key string) error
+}
+
+// DedupKey returns the deduplication key for a callback: transaction_id and status
+// (with a ":refund" suffix for is_refund callbacks, which are a distinct event).
+func DedupKey(p types.CallbackPayload) string {
+	key := p.TransactionID + ":" + string(p.Status)
+	if p.IsRefund {
+		key += ":refund"
+	}
+	return key
+}
+
+// MemoryDedupStore is an in-process DedupStore with TTL expiry and LRU eviction.
+// Entries are lost on restart; use FileDedupStore or a shared store when that matters.
+type MemoryDedupStore struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu    sync.Mutex
+	order *list.List // front = most recently claimed
+	items map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key     string
+	expires time.Time
+}
+
+// NewMemoryDedupStore creates a MemoryDedupStore. ttl <= 0 keeps entries until evicted;
+// maxEntries <= 0 means no LRU limit.
+func NewMemoryDedupStore(ttl time.Duration, maxEntries int) *MemoryDedupStore {
+	return &MemoryDedupStore{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Claim implements DedupStore.
+func (s *MemoryDedupStore) Claim(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if el, ok := s.items[key]; ok {
+		e := el.Value.(*memoryEntry)
+		if e.expires.IsZero() || now.Before(e.expires) {
+			s.order.MoveToFront(el)
+			return false, nil
+		}
+		s.order.Remove(el)
+		delete(s.items, key)
+	}
+	e := &memoryEntry{key: key}
+	if s.ttl > 0 {
+		e.expires = now.Add(s.ttl)
+	}
+	s.items[key] = s.order.PushFront(e)
+	for s.maxEntries > 0 && s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*memoryEntry).key)
+	}
+	return true, nil
+}
+
+// Release implements DedupStore.
+func (s *MemoryDedupStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		s.order.Remove(el)
+		delete(s.items, key)
+	}
+	return nil
+}
+
+// Len returns the number of entries currently held (including expired ones not yet evicted).
+func (s *MemoryDedupStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// Ensure MemoryDedupStore implements DedupStore
+var _ DedupStore = (*MemoryDedupStore)(nil)
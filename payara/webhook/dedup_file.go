@@ -0,0 +1,170 @@
+package webhook
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileDedupStore is a DedupStore persisted to a local append-only log file, so deduplication
+// survives restarts of a single instance. Each Claim/Release is appended and fsynced; the log is
+// compacted (expired and released entries dropped) when the store is opened.
+// It is not safe for several processes to share one file; use a shared store for multi-instance deployments.
+type FileDedupStore struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	f       *os.File
+	entries map[string]time.Time // key -> claim time
+}
+
+// OpenFileDedupStore opens (or creates) the log at path. ttl <= 0 keeps entries forever.
+func OpenFileDedupStore(path string, ttl time.Duration) (*FileDedupStore, error) {
+	s := &FileDedupStore{path: path, ttl: ttl, now: time.Now, entries: make(map[string]time.Time)}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load replays the log into memory. Lines are "C <unix_nano> <key>" (claim) or "R <unix_nano> <key>" (release).
+func (s *FileDedupStore) load() error {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		parts := strings.SplitN(sc.Text(), " ", 3)
+		if len(parts) != 3 {
+			continue // tolerate a torn last line
+		}
+		ns, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch parts[0] {
+		case "C":
+			s.entries[parts[2]] = time.Unix(0, ns)
+		case "R":
+			delete(s.entries, parts[2])
+		}
+	}
+	return sc.Err()
+}
+
+// compact rewrites the log with only live entries and opens it for appending.
+func (s *FileDedupStore) compact() error {
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for key, at := range s.entries {
+		if s.expired(at) {
+			delete(s.entries, key)
+			continue
+		}
+		fmt.Fprintf(w, "C %d %s\n", at.UnixNano(), key)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+	if dir, err := os.Open(filepath.Dir(s.path)); err == nil {
+		_ = dir.Sync()
+		dir.Close()
+	}
+	s.f, err = os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	return err
+}
+
+func (s *FileDedupStore) expired(at time.Time) bool {
+	return s.ttl > 0 && !s.now().Before(at.Add(s.ttl))
+}
+
+func (s *FileDedupStore) append(op string, at time.Time, key string) error {
+	if _, err := fmt.Fprintf(s.f, "%s %d %s\n", op, at.UnixNano(), key); err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+// Claim implements DedupStore.
+func (s *FileDedupStore) Claim(ctx context.Context, key string) (bool, error) {
+	if strings.ContainsAny(key, "\n\r") {
+		return false, fmt.Errorf("webhook: dedup key contains newline")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return false, os.ErrClosed
+	}
+	if at, ok := s.entries[key]; ok && !s.expired(at) {
+		return false, nil
+	}
+	now := s.now()
+	if err := s.append("C", now, key); err != nil {
+		return false, err
+	}
+	s.entries[key] = now
+	return true, nil
+}
+
+// Release implements DedupStore.
+func (s *FileDedupStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return os.ErrClosed
+	}
+	if _, ok := s.entries[key]; !ok {
+		return nil
+	}
+	if err := s.append("R", s.now(), key); err != nil {
+		return err
+	}
+	delete(s.entries, key)
+	return nil
+}
+
+// Close closes the underlying file.
+func (s *FileDedupStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+// Ensure FileDedupStore implements DedupStore
+var _ DedupStore = (*FileDedupStore)(nil)
@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+func TestDedupKey(t *testing.T) {
+	p := types.CallbackPayload{TransactionID: "T1", Status: types.CallbackStatusFailed}
+	if got := DedupKey(p); got != "T1:Failed" {
+		t.Errorf("got %q", got)
+	}
+	p.IsRefund = true
+	if got := DedupKey(p); got != "T1:Failed:refund" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestMemoryDedupStore_TTLAndLRU(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	s := NewMemoryDedupStore(time.Minute, 2)
+	s.now = func() time.Time { return now }
+
+	if ok, _ := s.Claim(ctx, "a"); !ok {
+		t.Fatal("first claim of a should succeed")
+	}
+	if ok, _ := s.Claim(ctx, "a"); ok {
+		t.Fatal("second claim of a should be a duplicate")
+	}
+	now = now.Add(2 * time.Minute)
+	if ok, _ := s.Claim(ctx, "a"); !ok {
+		t.Fatal("claim after TTL should succeed")
+	}
+	s.Claim(ctx, "b")
+	s.Claim(ctx, "c") // evicts a (least recently claimed)
+	if s.Len() != 2 {
+		t.Errorf("Len: got %d, want 2", s.Len())
+	}
+	if ok, _ := s.Claim(ctx, "a"); !ok {
+		t.Error("a should have been evicted")
+	}
+	_ = s.Release(ctx, "c")
+	if ok, _ := s.Claim(ctx, "c"); !ok {
+		t.Error("released key should be claimable")
+	}
+}
+
+func TestFileDedupStore_PersistsAcrossReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "dedup.log")
+	s, err := OpenFileDedupStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.Claim(ctx, "T1:Success"); !ok || err != nil {
+		t.Fatalf("claim: ok=%v err=%v", ok, err)
+	}
+	s.Claim(ctx, "T2:Success")
+	if err := s.Release(ctx, "T2:Success"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s2, err := OpenFileDedupStore(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s2.Close()
+	if ok, _ := s2.Claim(ctx, "T1:Success"); ok {
+		t.Error("T1 should still be claimed after reopen")
+	}
+	if ok, _ := s2.Claim(ctx, "T2:Success"); !ok {
+		t.Error("released T2 should be claimable after reopen")
+	}
+}
+
+func TestHandler_DedupSkipsDuplicatesAndReleasesOnFailure(t *testing.T) {
+	var calls int
+	fail := true
+	h := &Handler{
+		Dedup: NewMemoryDedupStore(time.Hour, 0),
+		OnSuccess: func(ctx context.Context, p types.CallbackPayload) error {
+			calls++
+			if fail {
+				return errors.New("db down")
+			}
+			return nil
+		},
+	}
+	body := `{"transaction_id":"T1","reference_id":"R1","status":"Success","amount":"10000","admin_fee":"0","is_refund":false}`
+
+	if rec := post(h, body); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("first delivery: got %d, want 500", rec.Code)
+	}
+	fail = false
+	if rec := post(h, body); rec.Code != http.StatusOK {
+		t.Fatalf("retry delivery: got %d, want 200", rec.Code)
+	}
+	if rec := post(h, body); rec.Code != http.StatusOK {
+		t.Fatalf("duplicate delivery: got %d, want 200", rec.Code)
+	}
+	if calls != 2 {
+		t.Errorf("callback calls: got %d, want 2 (failed + retry, duplicate skipped)", calls)
+	}
+}
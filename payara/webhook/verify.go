@@ -0,0 +1,221 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/turahe/payara-go-sdk/payara"
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// ErrVerificationFailed is wrapped by Verifier errors that prove a callback is not authentic.
+// Handler answers those with 403; any other Verifier error is treated as transient (500, Payara retries).
+var ErrVerificationFailed = errors.New("webhook: callback verification failed")
+
+// ErrStaleCallback is wrapped by Verifier errors for an authentic callback that a later final status has
+// superseded (e.g. a late Process delivery after Success). Handler acknowledges it with 200 without dispatch.
+var ErrStaleCallback = errors.New("webhook: stale callback")
+
+// Verifier checks that a callback really came from Payara. body is the raw request body.
+type Verifier interface {
+	Verify(r *http.Request, body []byte, payload types.CallbackPayload) error
+}
+
+// VerifierFunc adapts a function to Verifier.
+type VerifierFunc func(r *http.Request, body []byte, payload types.CallbackPayload) error
+
+// Verify implements Verifier.
+func (f VerifierFunc) Verify(r *http.Request, body []byte, payload types.CallbackPayload) error {
+	return f(r, body, payload)
+}
+
+// IPAllowlist accepts callbacks only from the configured addresses / CIDR ranges.
+type IPAllowlist struct {
+	nets []*net.IPNet
+	// ForwardedHeader, if set (e.g. "X-Forwarded-For"), takes the client IP from the right-most entry of that
+	// header instead of RemoteAddr. Only set it behind a proxy you control that appends to the header.
+	ForwardedHeader string
+}
+
+// NewIPAllowlist parses entries such as "203.0.113.10" or "203.0.113.0/24".
+func NewIPAllowlist(entries ...string) (*IPAllowlist, error) {
+	a := &IPAllowlist{}
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if !strings.Contains(e, "/") {
+			ip := net.ParseIP(e)
+			if ip == nil {
+				return nil, fmt.Errorf("webhook: invalid allowlist IP %q", e)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			a.nets = append(a.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(e)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: invalid allowlist CIDR %q: %w", e, err)
+		}
+		a.nets = append(a.nets, n)
+	}
+	return a, nil
+}
+
+// Verify implements Verifier.
+func (a *IPAllowlist) Verify(r *http.Request, body []byte, payload types.CallbackPayload) error {
+	ip := a.clientIP(r)
+	if ip == nil {
+		return fmt.Errorf("%w: cannot determine source IP", ErrVerificationFailed)
+	}
+	for _, n := range a.nets {
+		if n.Contains(ip) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: source IP %s not allowed", ErrVerificationFailed, ip)
+}
+
+func (a *IPAllowlist) clientIP(r *http.Request) net.IP {
+	if a.ForwardedHeader != "" {
+		if v := r.Header.Get(a.ForwardedHeader); v != "" {
+			parts := strings.Split(v, ",")
+			return net.ParseIP(strings.TrimSpace(parts[len(parts)-1]))
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// SharedSecretVerifier accepts callbacks whose Header equals Secret (constant-time comparison).
+type SharedSecretVerifier struct {
+	Header string // e.g. "X-Callback-Token"
+	Secret string
+}
+
+// Verify implements Verifier.
+func (v *SharedSecretVerifier) Verify(r *http.Request, body []byte, payload types.CallbackPayload) error {
+	got := r.Header.Get(v.Header)
+	if v.Secret == "" || subtle.ConstantTimeCompare([]byte(got), []byte(v.Secret)) != 1 {
+		return fmt.Errorf("%w: invalid %s", ErrVerificationFailed, v.Header)
+	}
+	return nil
+}
+
+// HMACVerifier accepts callbacks whose Header carries HMAC(Secret, raw body). Configure it to match the
+// scheme Payara publishes; defaults are HMAC-SHA256, hex encoded, with an optional "sha256=" style prefix stripped.
+type HMACVerifier struct {
+	Header string // e.g. "X-Payara-Signature"
+	Secret []byte
+	Hash   func() hash.Hash // Default sha256.New
+	Base64 bool             // Signature is base64 instead of hex
+	Prefix string           // Optional prefix before the signature, e.g. "sha256="
+}
+
+// Verify implements Verifier.
+func (v *HMACVerifier) Verify(r *http.Request, body []byte, payload types.CallbackPayload) error {
+	sig := strings.TrimPrefix(strings.TrimSpace(r.Header.Get(v.Header)), v.Prefix)
+	if sig == "" || len(v.Secret) == 0 {
+		return fmt.Errorf("%w: missing %s", ErrVerificationFailed, v.Header)
+	}
+	var got []byte
+	var err error
+	if v.Base64 {
+		got, err = base64.StdEncoding.DecodeString(sig)
+	} else {
+		got, err = hex.DecodeString(sig)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: malformed %s", ErrVerificationFailed, v.Header)
+	}
+	h := v.Hash
+	if h == nil {
+		h = sha256.New
+	}
+	mac := hmac.New(h, v.Secret)
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return fmt.Errorf("%w: signature mismatch", ErrVerificationFailed)
+	}
+	return nil
+}
+
+// StatusLookup is the subset of payara.TransferService used by LookupVerifier.
+type StatusLookup interface {
+	GetDisbursementStatus(ctx context.Context, id string) (*types.DisbursementStatusResponse, error)
+}
+
+// LookupVerifier confirms a callback by calling check-status for its transaction_id and comparing
+// reference_id, status and amount. A transfer that is still PROCESS while the callback reports a final
+// status is treated as transient (500, Payara retries later) rather than forged; a Process callback for a
+// transfer that is already final is stale (ErrStaleCallback). Only a Payara not-found error_code proves the
+// transaction does not exist; a bare 404 from a proxy or wrong route is transient.
+type LookupVerifier struct {
+	Transfers StatusLookup // e.g. client.Transfer()
+}
+
+// Verify implements Verifier.
+func (v *LookupVerifier) Verify(r *http.Request, body []byte, payload types.CallbackPayload) error {
+	resp, err := v.Transfers.GetDisbursementStatus(r.Context(), payload.TransactionID)
+	if err != nil {
+		if payara.IsNotFound(err) {
+			return fmt.Errorf("%w: transaction %s not found", ErrVerificationFailed, payload.TransactionID)
+		}
+		return err
+	}
+	d := resp.Data
+	if d == nil {
+		return fmt.Errorf("webhook: empty check-status response for %s", payload.TransactionID)
+	}
+	if d.ReferenceID != "" && d.ReferenceID != payload.ReferenceID {
+		return fmt.Errorf("%w: reference_id mismatch", ErrVerificationFailed)
+	}
+	if payload.Amount != d.Amount {
+		return fmt.Errorf("%w: amount mismatch", ErrVerificationFailed)
+	}
+	want := disbursementStatus(payload.Status)
+	if d.Status == want {
+		return nil
+	}
+	if d.Status == types.DisbursementStatusProcess {
+		return fmt.Errorf("webhook: transaction %s still %s, callback says %s", payload.TransactionID, d.Status, payload.Status)
+	}
+	if want == types.DisbursementStatusProcess {
+		return fmt.Errorf("%w: transaction %s is already %s", ErrStaleCallback, payload.TransactionID, d.Status)
+	}
+	return fmt.Errorf("%w: status mismatch (api %s, callback %s)", ErrVerificationFailed, d.Status, payload.Status)
+}
+
+func disbursementStatus(s types.CallbackStatus) types.DisbursementStatus {
+	switch s {
+	case types.CallbackStatusSuccess:
+		return types.DisbursementStatusSuccess
+	case types.CallbackStatusFailed:
+		return types.DisbursementStatusFailed
+	default:
+		return types.DisbursementStatusProcess
+	}
+}
+
+// Ensure verifiers implement Verifier
+var (
+	_ Verifier = (*IPAllowlist)(nil)
+	_ Verifier = (*SharedSecretVerifier)(nil)
+	_ Verifier = (*HMACVerifier)(nil)
+	_ Verifier = (*LookupVerifier)(nil)
+	_ Verifier = VerifierFunc(nil)
+)
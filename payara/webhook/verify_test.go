@@ -0,0 +1,154 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/turahe/payara-go-sdk/payara"
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+const successBody = `{"transaction_id":"T1","reference_id":"R1","status":"Success","amount":"10000","admin_fee":"3500","is_refund":false}`
+
+func postFrom(h http.Handler, remoteAddr string, header http.Header, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/callback/payara", strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIPAllowlist(t *testing.T) {
+	allow, err := NewIPAllowlist("203.0.113.0/24", "198.51.100.7", "2001:db8::/32")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dispatched int
+	h := &Handler{Verifiers: []Verifier{allow}, OnSuccess: func(ctx context.Context, p types.CallbackPayload) error {
+		dispatched++
+		return nil
+	}}
+	tests := []struct {
+		addr string
+		want int
+	}{
+		{"203.0.113.55:4000", http.StatusOK},
+		{"198.51.100.7:4000", http.StatusOK},
+		{"[2001:db8::1]:4000", http.StatusOK},
+		{"198.51.100.8:4000", http.StatusForbidden},
+		{"10.0.0.1:4000", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if rec := postFrom(h, tt.addr, nil, successBody); rec.Code != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.addr, rec.Code, tt.want)
+		}
+	}
+	if dispatched != 3 {
+		t.Errorf("dispatched: got %d, want 3", dispatched)
+	}
+	if _, err := NewIPAllowlist("not-an-ip"); err == nil {
+		t.Error("expected error for invalid entry")
+	}
+}
+
+func TestIPAllowlist_ForwardedHeader(t *testing.T) {
+	allow, _ := NewIPAllowlist("203.0.113.0/24")
+	allow.ForwardedHeader = "X-Forwarded-For"
+	h := &Handler{Verifiers: []Verifier{allow}}
+	// Left-most entries are client-controlled; only the right-most (appended by our proxy) counts.
+	spoofed := http.Header{"X-Forwarded-For": []string{"203.0.113.1, 10.9.9.9"}}
+	if rec := postFrom(h, "10.0.0.2:80", spoofed, successBody); rec.Code != http.StatusForbidden {
+		t.Errorf("spoofed: got %d", rec.Code)
+	}
+	real := http.Header{"X-Forwarded-For": []string{"10.9.9.9, 203.0.113.1"}}
+	if rec := postFrom(h, "10.0.0.2:80", real, successBody); rec.Code != http.StatusOK {
+		t.Errorf("proxied: got %d", rec.Code)
+	}
+}
+
+func TestSharedSecretAndHMACVerifier(t *testing.T) {
+	secret := []byte("s3cret")
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(successBody))
+	sig := hex.EncodeToString(mac.Sum(nil))
+
+	hm := &Handler{Verifiers: []Verifier{&HMACVerifier{Header: "X-Signature", Secret: secret, Prefix: "sha256="}}}
+	if rec := postFrom(hm, "1.2.3.4:1", http.Header{"X-Signature": []string{"sha256=" + sig}}, successBody); rec.Code != http.StatusOK {
+		t.Errorf("valid HMAC: got %d", rec.Code)
+	}
+	if rec := postFrom(hm, "1.2.3.4:1", http.Header{"X-Signature": []string{"sha256=" + sig}}, strings.Replace(successBody, "10000", "99999", 1)); rec.Code != http.StatusForbidden {
+		t.Errorf("tampered body: got %d", rec.Code)
+	}
+	if rec := postFrom(hm, "1.2.3.4:1", nil, successBody); rec.Code != http.StatusForbidden {
+		t.Errorf("missing HMAC: got %d", rec.Code)
+	}
+
+	ss := &Handler{Verifiers: []Verifier{&SharedSecretVerifier{Header: "X-Callback-Token", Secret: "tok"}}}
+	if rec := postFrom(ss, "1.2.3.4:1", http.Header{"X-Callback-Token": []string{"tok"}}, successBody); rec.Code != http.StatusOK {
+		t.Errorf("valid token: got %d", rec.Code)
+	}
+	if rec := postFrom(ss, "1.2.3.4:1", http.Header{"X-Callback-Token": []string{"nope"}}, successBody); rec.Code != http.StatusForbidden {
+		t.Errorf("bad token: got %d", rec.Code)
+	}
+}
+
+type fakeLookup struct {
+	data *types.DisbursementStatusData
+	err  error
+}
+
+func (f fakeLookup) GetDisbursementStatus(ctx context.Context, id string) (*types.DisbursementStatusResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &types.DisbursementStatusResponse{Success: true, Data: f.data}, nil
+}
+
+func TestLookupVerifier(t *testing.T) {
+	tests := []struct {
+		name   string
+		lookup fakeLookup
+		want   int
+	}{
+		{"match", fakeLookup{data: &types.DisbursementStatusData{TransactionID: "T1", ReferenceID: "R1", Status: types.DisbursementStatusSuccess, Amount: 10000}}, http.StatusOK},
+		{"forged status", fakeLookup{data: &types.DisbursementStatusData{TransactionID: "T1", ReferenceID: "R1", Status: types.DisbursementStatusFailed, Amount: 10000}}, http.StatusForbidden},
+		{"forged amount", fakeLookup{data: &types.DisbursementStatusData{TransactionID: "T1", ReferenceID: "R1", Status: types.DisbursementStatusSuccess, Amount: 5000}}, http.StatusForbidden},
+		{"not found", fakeLookup{err: &payara.APIError{HTTPStatus: 404, Code: "NOT_FOUND"}}, http.StatusForbidden},
+		{"bare 404", fakeLookup{err: &payara.APIError{HTTPStatus: 404, Message: "route not found"}}, http.StatusInternalServerError},
+		{"still processing", fakeLookup{data: &types.DisbursementStatusData{TransactionID: "T1", ReferenceID: "R1", Status: types.DisbursementStatusProcess, Amount: 10000}}, http.StatusInternalServerError},
+		{"lookup down", fakeLookup{err: errors.New("connection reset")}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		h := &Handler{Verifiers: []Verifier{&LookupVerifier{Transfers: tt.lookup}}}
+		if rec := postFrom(h, "1.2.3.4:1", nil, successBody); rec.Code != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestLookupVerifier_LateProcessIsAcknowledged(t *testing.T) {
+	dispatched := false
+	h := &Handler{
+		Verifiers: []Verifier{&LookupVerifier{Transfers: fakeLookup{data: &types.DisbursementStatusData{
+			TransactionID: "T1", ReferenceID: "R1", Status: types.DisbursementStatusSuccess, Amount: 10000}}}},
+		OnProcess: func(context.Context, types.CallbackPayload) error { dispatched = true; return nil },
+	}
+	body := strings.Replace(successBody, `"Success"`, `"Process"`, 1)
+	if rec := postFrom(h, "1.2.3.4:1", nil, body); rec.Code != http.StatusOK {
+		t.Errorf("got %d, want 200", rec.Code)
+	}
+	if dispatched {
+		t.Error("stale callback must not be dispatched")
+	}
+}
@@ -0,0 +1,192 @@
+// Package webhook provides an HTTP handler for Payara disbursement callbacks.
+// Doc: https://doc.payara.id/docs/1.0/callback
+// Configure your callback URL in the Payara merchant dashboard (Integrations).
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/turahe/payara-go-sdk/payara"
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+// MaxBodyBytes caps the callback body size read by Handler.
+const MaxBodyBytes = 1 << 20
+
+// CallbackFunc handles one decoded callback. Returning an error makes Handler reply 500 so Payara retries delivery.
+type CallbackFunc func(ctx context.Context, payload types.CallbackPayload) error
+
+// Handler is an http.Handler for Payara callbacks. It decodes types.CallbackPayload, validates it and
+// dispatches by status:
+//   - Success → OnSuccess
+//   - Failed with is_refund=true → OnRefund
+//   - Failed → OnFailed
+//   - Process → OnProcess
+//
+// Verifiers rejecting with ErrVerificationFailed yield 403; ErrStaleCallback is acknowledged (200) without
+// dispatch; other verifier errors yield 500 (Payara retries).
+// Nil callbacks are skipped (the delivery is still acknowledged). With Dedup set, each DedupKey is
+// dispatched at most once; a failed callback releases its key so the retry is processed. Responses: 200 {"status":"received"};
+// 400 on invalid payload; 405 on non-POST; 500 when a callback returns an error (Payara retries).
+type Handler struct {
+	OnSuccess CallbackFunc
+	OnFailed  CallbackFunc
+	OnRefund  CallbackFunc
+	OnProcess CallbackFunc
+	Dedup     DedupStore    // Optional; when set, duplicate deliveries (same DedupKey) are acknowledged without dispatch
+	Verifiers []Verifier    // Optional; all must pass before dedup and dispatch (see IPAllowlist, HMACVerifier, LookupVerifier)
+	Logger    payara.Logger // Optional; nil uses payara.NopLogger
+}
+
+// ErrInvalidPayload is wrapped by Validate errors.
+var ErrInvalidPayload = errors.New("webhook: invalid callback payload")
+
+// Validate checks required fields (transaction_id, reference_id, status) and that status is a documented value.
+func Validate(p *types.CallbackPayload) error {
+	var missing []string
+	if p.TransactionID == "" {
+		missing = append(missing, "transaction_id")
+	}
+	if p.ReferenceID == "" {
+		missing = append(missing, "reference_id")
+	}
+	if p.Status == "" {
+		missing = append(missing, "status")
+	}
+	if len(missing) > 0 {
+		return errors.Join(ErrInvalidPayload, errors.New("missing required fields: "+strings.Join(missing, ", ")))
+	}
+	switch p.Status {
+	case types.CallbackStatusSuccess, types.CallbackStatusFailed, types.CallbackStatusProcess:
+		return nil
+	default:
+		return errors.Join(ErrInvalidPayload, errors.New("unknown status: "+string(p.Status)))
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := h.logger()
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid content type"})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	if err != nil {
+		logger.Warn("payara callback: read error", "error", err)
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid body"})
+		return
+	}
+	var payload types.CallbackPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		logger.Warn("payara callback: decode error", "error", err)
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+		return
+	}
+	if err := Validate(&payload); err != nil {
+		logger.Warn("payara callback: invalid payload", "error", err, "transaction_id", payload.TransactionID)
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	var stale error
+	for _, v := range h.Verifiers {
+		if err := v.Verify(r, body, payload); err != nil {
+			if errors.Is(err, ErrStaleCallback) {
+				stale = err
+				continue
+			}
+			if errors.Is(err, ErrVerificationFailed) {
+				logger.Warn("payara callback: rejected", "error", err, "transaction_id", payload.TransactionID, "remote_addr", r.RemoteAddr)
+				writeJSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+				return
+			}
+			logger.Error("payara callback: verification error", "error", err, "transaction_id", payload.TransactionID)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "verification unavailable"})
+			return
+		}
+	}
+	if stale != nil {
+		logger.Info("payara callback: stale delivery acknowledged", "reason", stale, "transaction_id", payload.TransactionID)
+		writeJSON(w, http.StatusOK, map[string]string{"status": "received"})
+		return
+	}
+
+	logger.Info("payara callback", "reference_id", payload.ReferenceID, "transaction_id", payload.TransactionID,
+		"status", payload.Status, "amount", payload.Amount, "admin_fee", payload.AdminFee, "is_refund", payload.IsRefund)
+
+	ctx := r.Context()
+	key := DedupKey(payload)
+	if h.Dedup != nil {
+		first, err := h.Dedup.Claim(ctx, key)
+		if err != nil {
+			logger.Error("payara callback: dedup store failed", "error", err, "key", key)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "processing failed"})
+			return
+		}
+		if !first {
+			logger.Info("payara callback: duplicate delivery skipped", "key", key)
+			writeJSON(w, http.StatusOK, map[string]string{"status": "received"})
+			return
+		}
+	}
+
+	if err := h.dispatch(ctx, payload); err != nil {
+		logger.Error("payara callback: handler failed", "error", err, "transaction_id", payload.TransactionID)
+		if h.Dedup != nil {
+			// Let Payara's retry delivery reach the callback again.
+			if rerr := h.Dedup.Release(ctx, key); rerr != nil {
+				logger.Error("payara callback: dedup release failed", "error", rerr, "key", key)
+			}
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "processing failed"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "received"})
+}
+
+// dispatch routes the payload to the callback for its status.
+func (h *Handler) dispatch(ctx context.Context, p types.CallbackPayload) error {
+	var fn CallbackFunc
+	switch {
+	case p.Status == types.CallbackStatusSuccess:
+		fn = h.OnSuccess
+	case p.Status == types.CallbackStatusFailed && p.IsRefund:
+		fn = h.OnRefund
+	case p.Status == types.CallbackStatusFailed:
+		fn = h.OnFailed
+	case p.Status == types.CallbackStatusProcess:
+		fn = h.OnProcess
+	}
+	if fn == nil {
+		return nil
+	}
+	return fn(ctx, p)
+}
+
+func (h *Handler) logger() payara.Logger {
+	if h.Logger == nil {
+		return payara.NopLogger{}
+	}
+	return h.Logger
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// Ensure Handler implements http.Handler
+var _ http.Handler = (*Handler)(nil)
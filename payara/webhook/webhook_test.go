@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/turahe/payara-go-sdk/payara/types"
+)
+
+func post(h http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/callback/payara", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_Dispatch(t *testing.T) {
+	var got []string
+	record := func(name string) CallbackFunc {
+		return func(ctx context.Context, p types.CallbackPayload) error {
+			got = append(got, name+":"+p.TransactionID)
+			return nil
+		}
+	}
+	h := &Handler{OnSuccess: record("success"), OnFailed: record("failed"), OnRefund: record("refund"), OnProcess: record("process")}
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"transaction_id":"T1","reference_id":"R1","status":"Success","amount":"10000","admin_fee":"3500","is_refund":false}`, "success:T1"},
+		{`{"transaction_id":"T2","reference_id":"R2","status":"Failed","amount":"10000","admin_fee":"3500","is_refund":false}`, "failed:T2"},
+		{`{"transaction_id":"T3","reference_id":"R3","status":"Failed","amount":"10000","admin_fee":"3500","is_refund":true}`, "refund:T3"},
+		{`{"transaction_id":"T4","reference_id":"R4","status":"Process","amount":"10000","admin_fee":"3500","is_refund":false}`, "process:T4"},
+	}
+	for _, tt := range tests {
+		got = nil
+		rec := post(h, tt.body)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status %d", tt.want, rec.Code)
+		}
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("dispatch: got %v, want %s", got, tt.want)
+		}
+		if !strings.Contains(rec.Body.String(), `"received"`) {
+			t.Errorf("body: %s", rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_CallbackErrorReturns500(t *testing.T) {
+	h := &Handler{OnSuccess: func(ctx context.Context, p types.CallbackPayload) error { return errors.New("db down") }}
+	rec := post(h, `{"transaction_id":"T1","reference_id":"R1","status":"Success","amount":"10000","admin_fee":"0","is_refund":false}`)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status: got %d, want 500", rec.Code)
+	}
+}
+
+func TestHandler_Rejects(t *testing.T) {
+	h := &Handler{}
+	tests := []struct {
+		name   string
+		method string
+		ctype  string
+		body   string
+		want   int
+	}{
+		{"method", http.MethodGet, "application/json", "", http.StatusMethodNotAllowed},
+		{"content type", http.MethodPost, "text/plain", `{}`, http.StatusBadRequest},
+		{"bad json", http.MethodPost, "application/json", `{`, http.StatusBadRequest},
+		{"missing fields", http.MethodPost, "application/json", `{"transaction_id":"T1"}`, http.StatusBadRequest},
+		{"unknown status", http.MethodPost, "application/json", `{"transaction_id":"T1","reference_id":"R1","status":"Weird"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/callback/payara", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.ctype)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	err := Validate(&types.CallbackPayload{TransactionID: "T1"})
+	if !errors.Is(err, ErrInvalidPayload) || !strings.Contains(err.Error(), "reference_id, status") {
+		t.Errorf("got %v", err)
+	}
+	if err := Validate(&types.CallbackPayload{TransactionID: "T1", ReferenceID: "R1", Status: types.CallbackStatusProcess}); err != nil {
+		t.Errorf("valid payload: %v", err)
+	}
+}